@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/saurav-tiwari03/go-find/walk"
+)
+
+/* -------------------- options -------------------- */
+
+// walkOpts collects the options that shape the scan itself.
+var walkOpts = defaultWalkOpts
+
+var defaultWalkOpts = walk.Options{Gitignore: true}
+
+// option is one of go-find's own command-line switches, as opposed to the
+// tests of a find expression.
+type option struct {
+	hasValue bool
+	set      func(value string) error
+}
+
+var options = map[string]option{
+	"-L":                {true, setDepth},
+	"--no-ignore":       {false, func(string) error { walkOpts.Gitignore = false; return nil }},
+	"--all":             {false, func(string) error { walkOpts.Hidden = walk.ShowHidden; return nil }},
+	"--hidden-shallow":  {false, func(string) error { walkOpts.Hidden = walk.ShallowHidden; return nil }},
+	"--follow":          {false, func(string) error { walkOpts.FollowLinks = true; return nil }},
+	"--one-file-system": {false, func(string) error { walkOpts.OneFileSystem = true; return nil }},
+	"--jobs":            {true, setJobs},
+	"--exclude":         {true, func(v string) error { walkOpts.Exclude = append(walkOpts.Exclude, v); return nil }},
+	"--include":         {true, func(v string) error { walkOpts.Include = append(walkOpts.Include, v); return nil }},
+	"--show-excluded":   {false, func(string) error { walkOpts.ShowExcluded = true; return nil }},
+	"--counts":          {false, func(string) error { showCounts = true; return nil }},
+	"--top":             {true, setTop},
+	"--sort":            {true, setSort},
+	"--reverse":         {false, func(string) error { walkOpts.Order.Reverse = true; return nil }},
+	"--mixed":           {false, func(string) error { walkOpts.Order.Mixed = true; return nil }},
+	"--contains":        {true, setContains},
+	"--vimgrep":         {false, func(string) error { vimgrep = true; return nil }},
+	"--output":          {true, setOutput},
+	"--comments":        {true, setComments},
+	"--columns":         {true, setColumns},
+	"--dot-files":       {false, func(string) error { dotFiles = true; return nil }},
+	"--color-by":        {true, setTreemapColor},
+}
+
+func setJobs(value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return fmt.Errorf("invalid job count %q", value)
+	}
+	walkOpts.Jobs = n
+	return nil
+}
+
+func setSort(key string) error {
+	if !walk.ValidSortKey(key) {
+		return fmt.Errorf("unknown sort key %q", key)
+	}
+	walkOpts.Order.Key = key
+	return nil
+}
+
+// splitOptions applies every known option in args and returns the rest,
+// which holds the target directory and the find expression. Values are
+// given as "--opt VALUE" or "--opt=VALUE".
+func splitOptions(args []string) ([]string, error) {
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		name, value, inline := strings.Cut(args[i], "=")
+		opt, ok := options[name]
+		if !ok {
+			if strings.HasPrefix(args[i], "--") {
+				return nil, fmt.Errorf("unknown option %s", name)
+			}
+			rest = append(rest, args[i])
+			continue
+		}
+
+		switch {
+		case opt.hasValue && !inline:
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("missing argument to %s", name)
+			}
+			i++
+			value = args[i]
+		case !opt.hasValue && inline:
+			return nil, fmt.Errorf("%s takes no argument", name)
+		}
+		if err := opt.set(value); err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	return rest, nil
+}
+
+/* -------------------- arguments -------------------- */
+
+// resetArgs restores everything parseArgs sets that a tree rendering
+// depends on, so another set of arguments can be parsed.
+func resetArgs() {
+	walkOpts = defaultWalkOpts
+	maxDepth = 0
+	expression = nil
+	contentPattern = nil
+	vimgrep = false
+	comments = nil
+}
+
+// takeAll consumes any leading "-a" as --all. Inside an expression "-a" is
+// find's AND operator instead; the parser tells the two apart.
+func takeAll(args []string) []string {
+	for len(args) > 0 && args[0] == "-a" {
+		walkOpts.Hidden = walk.ShowHidden
+		args = args[1:]
+	}
+	return args
+}
+
+// parseArgs reads the options, target directory and find expression. The
+// directory may come before or after the expression and defaults to ".".
+func parseArgs(args []string) (string, error) {
+	args, err := splitOptions(args)
+	if err != nil {
+		return "", err
+	}
+
+	targetDir := ""
+	args = takeAll(args)
+	if len(args) > 0 && !isExprToken(args[0]) {
+		targetDir = args[0]
+		args = takeAll(args[1:])
+	}
+
+	if len(args) > 0 {
+		p := &exprParser{args: args}
+		expr, err := p.parse()
+		if err != nil {
+			return "", err
+		}
+		expression = expr
+		args = p.args[p.pos:]
+	}
+
+	args = takeAll(args)
+	if len(args) > 0 && targetDir == "" && !isExprToken(args[0]) {
+		targetDir = args[0]
+		args = args[1:]
+	}
+	args = takeAll(args)
+	if len(args) > 0 {
+		return "", fmt.Errorf("unexpected argument %s", args[0])
+	}
+
+	// quickfix lines are content matches, so there is nothing to print
+	// without a search
+	if vimgrep && contentPattern == nil {
+		return "", fmt.Errorf("--vimgrep needs --contains")
+	}
+
+	// content search narrows whatever the expression selected
+	if contentPattern != nil {
+		if expression == nil {
+			expression = containsTest
+		} else {
+			expr := expression
+			expression = func(n *walk.Entry) bool { return expr(n) && containsTest(n) }
+		}
+	}
+
+	if targetDir == "" {
+		targetDir = "."
+	}
+	findRoot, scanRoot = targetDir, targetDir
+	return targetDir, nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saurav-tiwari03/go-find/walk"
+)
+
+/* -------------------- CSV and TSV output -------------------- */
+
+// columns are the fields of every row of the tabular output, picked with
+// --columns.
+var columns = []string{"path", "type", "size", "mtime", "mode", "owner", "extension", "depth"}
+
+// columnValues formats each column for an entry found depth levels below
+// the root.
+var columnValues = map[string]func(e *walk.Entry, depth int) string{
+	"path":      func(e *walk.Entry, _ int) string { return e.Path },
+	"name":      func(e *walk.Entry, _ int) string { return e.Name },
+	"type":      func(e *walk.Entry, _ int) string { return entryType(e) },
+	"size":      func(e *walk.Entry, _ int) string { return strconv.FormatInt(e.Size, 10) },
+	"mtime":     func(e *walk.Entry, _ int) string { return e.ModTime.UTC().Format(time.RFC3339) },
+	"mode":      func(e *walk.Entry, _ int) string { return fmt.Sprintf("%04o", unixPerm(e.Mode)) },
+	"owner":     func(e *walk.Entry, _ int) string { return userName(e.UID) },
+	"group":     func(e *walk.Entry, _ int) string { return groupName(e.GID) },
+	"extension": func(e *walk.Entry, _ int) string { return extension(e) },
+	"depth":     func(_ *walk.Entry, depth int) string { return strconv.Itoa(depth) },
+	"hash":      func(e *walk.Entry, _ int) string { return fileHash(e) },
+}
+
+func setColumns(value string) error {
+	var picked []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if _, ok := columnValues[name]; !ok {
+			return fmt.Errorf("unknown column %q", name)
+		}
+		picked = append(picked, name)
+	}
+	columns = picked
+	return nil
+}
+
+func renderCSV(w io.Writer, res *walk.Result) error {
+	return writeTable(w, res, ',')
+}
+
+func renderTSV(w io.Writer, res *walk.Result) error {
+	return writeTable(w, res, '\t')
+}
+
+// writeTable writes a header and a row for every scanned entry, parents
+// before their children.
+func writeTable(w io.Writer, res *walk.Result, sep rune) error {
+	out := csv.NewWriter(w)
+	out.Comma = sep
+	out.Write(columns)
+	tableRows(out, res.Root, 0)
+	out.Flush()
+	return out.Error()
+}
+
+func tableRows(out *csv.Writer, e *walk.Entry, depth int) {
+	if e.Placeholder != "" {
+		return
+	}
+	row := make([]string, len(columns))
+	for i, name := range columns {
+		row[i] = columnValues[name](e, depth)
+	}
+	out.Write(row)
+
+	if e.IsDir && expanded(e, depth) {
+		for _, child := range walk.Ordered(e, walkOpts.Order) {
+			tableRows(out, child, depth+1)
+		}
+	}
+}
+
+// extension is a file's extension without the dot.
+func extension(e *walk.Entry) string {
+	if e.IsDir {
+		return ""
+	}
+	return strings.TrimPrefix(filepath.Ext(e.Name), ".")
+}
+
+// fileHash is the SHA-256 of a regular file's content, or empty for
+// anything else.
+func fileHash(e *walk.Entry) string {
+	if e.IsDir || !e.Mode.IsRegular() {
+		return ""
+	}
+	f, err := openFile(e.Path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// userNames and groupNames cache id lookups, which read /etc/passwd and
+// /etc/group or ask a directory service.
+var userNames, groupNames = map[string]string{}, map[string]string{}
+
+// userName returns the login name of a numeric user id, or the id itself
+// when it has none.
+func userName(uid string) string {
+	name, ok := userNames[uid]
+	if !ok {
+		name = uid
+		if u, err := user.LookupId(uid); err == nil {
+			name = u.Username
+		}
+		userNames[uid] = name
+	}
+	return name
+}
+
+// groupName returns the name of a numeric group id, or the id itself when
+// it has none.
+func groupName(gid string) string {
+	name, ok := groupNames[gid]
+	if !ok {
+		name = gid
+		if g, err := user.LookupGroupId(gid); err == nil {
+			name = g.Name
+		}
+		groupNames[gid] = name
+	}
+	return name
+}
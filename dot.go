@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/saurav-tiwari03/go-find/walk"
+)
+
+/* -------------------- Graphviz output -------------------- */
+
+// dotFiles adds files to the DOT graph, which otherwise shows directories
+// only.
+var dotFiles bool
+
+// renderDOT writes the tree as a Graphviz digraph, one node per directory
+// labelled with its name and size and an edge to each child, ready for
+// dot -Tsvg.
+func renderDOT(w io.Writer, res *walk.Result) error {
+	fmt.Fprintln(w, "digraph go_find {")
+	fmt.Fprintln(w, "\trankdir=LR;")
+	fmt.Fprintln(w, "\tnode [shape=folder, fontname=\"Helvetica\", fontsize=10];")
+	fmt.Fprintln(w, "\tedge [color=\"#888888\"];")
+	id := 0
+	dotNode(w, res.Root, dotEscape(rootLabel(res.Root)), &id, 0)
+	fmt.Fprintln(w, "}")
+	return nil
+}
+
+// dotNode writes the node of e, which is depth levels below the root,
+// with the next free id, followed by the nodes and edges below it. name is
+// its label, already escaped.
+func dotNode(w io.Writer, e *walk.Entry, name string, id *int, depth int) {
+	node := *id
+	*id++
+
+	label := name + "\\n" + humanSize(e.Size)
+	attrs := ""
+	switch {
+	case e.Placeholder != "":
+		label = name + "\\n[" + dotEscape(e.Placeholder) + "]"
+		attrs = ", style=dashed, fontcolor=\"#888888\""
+	case !e.IsDir:
+		attrs = ", shape=note"
+	}
+	fmt.Fprintf(w, "\tn%d [label=\"%s\"%s];\n", node, label, attrs)
+
+	if !e.IsDir || !expanded(e, depth) {
+		return
+	}
+	for _, child := range walk.Ordered(e, walkOpts.Order) {
+		if !child.IsDir && !dotFiles {
+			continue
+		}
+		childName := dotEscape(child.Name)
+		if child.IsDir {
+			childName += "/"
+		}
+		fmt.Fprintf(w, "\tn%d -> n%d;\n", node, *id)
+		dotNode(w, child, childName, id, depth+1)
+	}
+}
+
+// dotEscape quotes s for use inside a double-quoted DOT string.
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
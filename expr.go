@@ -0,0 +1,424 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saurav-tiwari03/go-find/walk"
+)
+
+/* -------------------- expression parser -------------------- */
+
+// exprParser is a recursive-descent parser for find(1) expressions:
+//
+//	expr    = and { ("-o" | "-or") and }
+//	and     = not { ["-a" | "-and"] not }
+//	not     = ("!" | "-not") not | primary
+//	primary = "(" expr ")" | test
+type exprParser struct {
+	args []string
+	pos  int
+}
+
+// isExprToken reports whether arg belongs to a find expression rather than
+// naming the directory to scan.
+func isExprToken(arg string) bool {
+	return arg == "(" || arg == ")" || arg == "!" || strings.HasPrefix(arg, "-") && arg != "-"
+}
+
+func (p *exprParser) peek() string {
+	if p.pos < len(p.args) {
+		return p.args[p.pos]
+	}
+	return ""
+}
+
+func (p *exprParser) next() string {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
+
+// parse reads one expression and stops at the first argument that cannot
+// continue it, leaving the rest to the caller.
+func (p *exprParser) parse() (predicate, error) {
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() == ")" {
+		return nil, fmt.Errorf("unmatched )")
+	}
+	return expr, nil
+}
+
+func (p *exprParser) parseOr() (predicate, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "-o" || p.peek() == "-or" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(n *walk.Entry) bool { return l(n) || right(n) }
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (predicate, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		switch {
+		case tok == "-a" && !p.continues(p.pos+1):
+			// "-a" ending the expression is -a/--all, left to parseArgs
+			return left, nil
+		case tok == "-a" || tok == "-and":
+			p.next()
+		case tok == "" || tok == ")" || tok == "-o" || tok == "-or" || !isExprToken(tok):
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(n *walk.Entry) bool { return l(n) && right(n) }
+	}
+}
+
+// continues reports whether the argument at i can start an operand.
+func (p *exprParser) continues(i int) bool {
+	if i >= len(p.args) {
+		return false
+	}
+	tok := p.args[i]
+	return isExprToken(tok) && tok != ")" && tok != "-o" && tok != "-or" && tok != "-a" && tok != "-and"
+}
+
+func (p *exprParser) parseNot() (predicate, error) {
+	if p.peek() == "!" || p.peek() == "-not" {
+		p.next()
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return func(n *walk.Entry) bool { return !expr(n) }, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (predicate, error) {
+	tok := p.next()
+	switch tok {
+	case "":
+		return nil, fmt.Errorf("expected an expression")
+	case "(":
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+		return expr, nil
+	case ")", "-o", "-or", "-a", "-and":
+		return nil, fmt.Errorf("expected an expression before %s", tok)
+	}
+
+	if test, ok := flagTests[tok]; ok {
+		return test, nil
+	}
+	test, ok := argTests[tok]
+	if !ok {
+		return nil, fmt.Errorf("unknown predicate %s", tok)
+	}
+	if p.pos >= len(p.args) {
+		return nil, fmt.Errorf("missing argument to %s", tok)
+	}
+	expr, err := test(p.next())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", tok, err)
+	}
+	return expr, nil
+}
+
+/* -------------------- tests -------------------- */
+
+// flagTests take no argument.
+var flagTests = map[string]predicate{
+	"-true":  func(n *walk.Entry) bool { return true },
+	"-false": func(n *walk.Entry) bool { return false },
+	"-empty": func(n *walk.Entry) bool {
+		if n.IsDir {
+			return len(n.Children) == 0
+		}
+		return n.Mode.IsRegular() && n.Size == 0
+	},
+}
+
+// argTests build a predicate from their argument.
+var argTests = map[string]func(arg string) (predicate, error){
+	"-name":  nameTest(false),
+	"-iname": nameTest(true),
+	"-path":  pathTest(false),
+	"-ipath": pathTest(true),
+	"-type":  typeTest,
+	"-size":  sizeTest,
+	"-mtime": ageTest(24 * time.Hour),
+	"-mmin":  ageTest(time.Minute),
+	"-perm":  permTest,
+	"-user":  userTest,
+	"-group": groupTest,
+}
+
+func nameTest(foldCase bool) func(string) (predicate, error) {
+	return func(pattern string) (predicate, error) {
+		re, err := globRegexp(pattern, foldCase)
+		if err != nil {
+			return nil, err
+		}
+		return func(n *walk.Entry) bool { return re.MatchString(n.Name) }, nil
+	}
+}
+
+func pathTest(foldCase bool) func(string) (predicate, error) {
+	return func(pattern string) (predicate, error) {
+		re, err := globRegexp(pattern, foldCase)
+		if err != nil {
+			return nil, err
+		}
+		return func(n *walk.Entry) bool { return re.MatchString(findPath(n)) }, nil
+	}
+}
+
+func typeTest(arg string) (predicate, error) {
+	var types []byte
+	for _, t := range strings.Split(arg, ",") {
+		if len(t) != 1 || !strings.Contains("fdlpsbc", t) {
+			return nil, fmt.Errorf("unknown type %q", t)
+		}
+		types = append(types, t[0])
+	}
+	return func(n *walk.Entry) bool {
+		for _, t := range types {
+			if fileType(n) == t {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// fileType returns the find -type letter of an entry.
+func fileType(n *walk.Entry) byte {
+	switch {
+	case n.IsDir:
+		return 'd'
+	case n.Mode&fs.ModeSymlink != 0:
+		return 'l'
+	case n.Mode&fs.ModeNamedPipe != 0:
+		return 'p'
+	case n.Mode&fs.ModeSocket != 0:
+		return 's'
+	case n.Mode&fs.ModeCharDevice != 0:
+		return 'c'
+	case n.Mode&fs.ModeDevice != 0:
+		return 'b'
+	}
+	return 'f'
+}
+
+// parseNumeric splits a find numeric argument into its comparison (+1 for
+// "+N", -1 for "-N", 0 for "N"), the number and any trailing unit suffix.
+func parseNumeric(arg string) (cmp int, n int64, suffix string, err error) {
+	switch {
+	case strings.HasPrefix(arg, "+"):
+		cmp, arg = 1, arg[1:]
+	case strings.HasPrefix(arg, "-"):
+		cmp, arg = -1, arg[1:]
+	}
+	end := 0
+	for end < len(arg) && arg[end] >= '0' && arg[end] <= '9' {
+		end++
+	}
+	if end == 0 {
+		return 0, 0, "", fmt.Errorf("invalid number %q", arg)
+	}
+	n, err = strconv.ParseInt(arg[:end], 10, 64)
+	return cmp, n, arg[end:], err
+}
+
+func compareNumeric(cmp int, value, n int64) bool {
+	switch cmp {
+	case 1:
+		return value > n
+	case -1:
+		return value < n
+	}
+	return value == n
+}
+
+// sizeTest follows find: the size is rounded up to whole units before it is
+// compared, and the default unit is a 512-byte block. Like find it tests a
+// directory's own size, not the total below it.
+func sizeTest(arg string) (predicate, error) {
+	cmp, want, suffix, err := parseNumeric(arg)
+	if err != nil {
+		return nil, err
+	}
+	units := map[string]int64{"": 512, "b": 512, "c": 1, "w": 2, "k": 1 << 10, "M": 1 << 20, "G": 1 << 30}
+	unit, ok := units[suffix]
+	if !ok {
+		return nil, fmt.Errorf("unknown unit %q", suffix)
+	}
+	return func(n *walk.Entry) bool {
+		return compareNumeric(cmp, (n.StatSize+unit-1)/unit, want)
+	}, nil
+}
+
+// ageTest compares how many whole units ago an entry was modified, as
+// -mtime does with days and -mmin with minutes.
+func ageTest(unit time.Duration) func(string) (predicate, error) {
+	return func(arg string) (predicate, error) {
+		cmp, want, suffix, err := parseNumeric(arg)
+		if err != nil {
+			return nil, err
+		}
+		if suffix != "" {
+			return nil, fmt.Errorf("invalid number %q", arg)
+		}
+		now := time.Now()
+		return func(n *walk.Entry) bool {
+			return compareNumeric(cmp, int64(now.Sub(n.ModTime)/unit), want)
+		}, nil
+	}
+}
+
+// permTest supports find's exact ("MODE"), all-of ("-MODE") and any-of
+// ("/MODE") forms with octal or symbolic modes.
+func permTest(arg string) (predicate, error) {
+	kind := byte(0)
+	if strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "/") {
+		kind, arg = arg[0], arg[1:]
+	}
+	want, err := parseMode(arg)
+	if err != nil {
+		return nil, err
+	}
+	return func(n *walk.Entry) bool {
+		perm := unixPerm(n.Mode)
+		switch kind {
+		case '-':
+			return perm&want == want
+		case '/':
+			return want == 0 || perm&want != 0
+		}
+		return perm == want
+	}, nil
+}
+
+// unixPerm converts a FileMode into the classic 12 permission bits.
+func unixPerm(m fs.FileMode) uint32 {
+	perm := uint32(m.Perm())
+	if m&fs.ModeSetuid != 0 {
+		perm |= 04000
+	}
+	if m&fs.ModeSetgid != 0 {
+		perm |= 02000
+	}
+	if m&fs.ModeSticky != 0 {
+		perm |= 01000
+	}
+	return perm
+}
+
+// parseMode reads an octal mode or a chmod-style symbolic mode such as
+// "u+rwx,g+rx" applied to an empty mode.
+func parseMode(arg string) (uint32, error) {
+	if arg != "" && arg[0] >= '0' && arg[0] <= '7' {
+		mode, err := strconv.ParseUint(arg, 8, 32)
+		if err != nil || mode > 07777 {
+			return 0, fmt.Errorf("invalid mode %q", arg)
+		}
+		return uint32(mode), nil
+	}
+
+	var mode uint32
+	for _, clause := range strings.Split(arg, ",") {
+		i := 0
+		var who uint32
+		for ; i < len(clause) && strings.IndexByte("ugoa", clause[i]) >= 0; i++ {
+			who |= map[byte]uint32{'u': 04700, 'g': 02070, 'o': 01007, 'a': 07777}[clause[i]]
+		}
+		if who == 0 {
+			who = 07777
+		}
+		if i >= len(clause) || strings.IndexByte("+-=", clause[i]) < 0 {
+			return 0, fmt.Errorf("invalid mode %q", arg)
+		}
+		op := clause[i]
+		var bits uint32
+		for _, c := range clause[i+1:] {
+			switch c {
+			case 'r':
+				bits |= 0444
+			case 'w':
+				bits |= 0222
+			case 'x':
+				bits |= 0111
+			case 's':
+				bits |= 06000
+			case 't':
+				bits |= 01000
+			default:
+				return 0, fmt.Errorf("invalid mode %q", arg)
+			}
+		}
+		bits &= who
+		switch op {
+		case '+':
+			mode |= bits
+		case '-':
+			mode &^= bits
+		case '=':
+			mode = mode&^who | bits
+		}
+	}
+	return mode, nil
+}
+
+func userTest(name string) (predicate, error) {
+	uid := name
+	if _, err := strconv.Atoi(name); err != nil {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return nil, fmt.Errorf("unknown user %q", name)
+		}
+		uid = u.Uid
+	}
+	return func(n *walk.Entry) bool { return n.UID == uid }, nil
+}
+
+func groupTest(name string) (predicate, error) {
+	gid := name
+	if _, err := strconv.Atoi(name); err != nil {
+		g, err := user.LookupGroup(name)
+		if err != nil {
+			return nil, fmt.Errorf("unknown group %q", name)
+		}
+		gid = g.Gid
+	}
+	return func(n *walk.Entry) bool { return n.GID == gid }, nil
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/saurav-tiwari03/go-find/walk"
+)
+
+var (
+	logFile = &walk.Entry{Name: "a.log", Path: "a.log"}
+	txtFile = &walk.Entry{Name: "b.txt", Path: "b.txt"}
+	logDir  = &walk.Entry{Name: "c.log", Path: "c.log", IsDir: true, Mode: fs.ModeDir}
+)
+
+func TestParseArgsAll(t *testing.T) {
+	tests := []struct {
+		args []string
+		dir  string
+		all  bool
+		// matches lists which of logFile, txtFile and logDir the
+		// expression selects, or is empty when there is no expression
+		matches string
+	}{
+		{[]string{"-a"}, ".", true, ""},
+		{[]string{"--all"}, ".", true, ""},
+		{[]string{"-a", "/tmp"}, "/tmp", true, ""},
+		{[]string{"/tmp", "-a"}, "/tmp", true, ""},
+		{[]string{"-a", "-name", "*.log"}, ".", true, "ld"},
+		{[]string{"-name", "*.log", "-a"}, ".", true, "ld"},
+		{[]string{"-name", "*.log", "-a", "/tmp"}, "/tmp", true, "ld"},
+		{[]string{"/tmp", "-name", "*.log", "-a"}, "/tmp", true, "ld"},
+		{[]string{"-name", "*.log", "-a", "-a"}, ".", true, "ld"},
+		{[]string{"-name", "*.log", "-a", "-type", "f"}, ".", false, "l"},
+		{[]string{"-name", "*.log", "-a", "!", "-type", "d"}, ".", false, "l"},
+		{[]string{"-name", "*.log", "-a", "(", "-type", "d", ")"}, ".", false, "d"},
+		{[]string{"-name", "*.log", "-and", "-type", "f"}, ".", false, "l"},
+		{[]string{"-name", "*.log", "-type", "f", "-a"}, ".", true, "l"},
+		{[]string{"-a", "-name", "*.log", "-a", "-type", "d", "-a", "/tmp"}, "/tmp", true, "d"},
+		{[]string{"-name", "*.txt", "-o", "-type", "d"}, ".", false, "td"},
+		{[]string{"-not", "-name", "*.log"}, ".", false, "t"},
+	}
+	for _, tt := range tests {
+		resetArgs()
+		dir, err := parseArgs(tt.args)
+		if err != nil {
+			t.Errorf("parseArgs(%q): %v", tt.args, err)
+			continue
+		}
+		if dir != tt.dir {
+			t.Errorf("parseArgs(%q) directory = %q, want %q", tt.args, dir, tt.dir)
+		}
+		if all := walkOpts.Hidden == walk.ShowHidden; all != tt.all {
+			t.Errorf("parseArgs(%q) all = %v, want %v", tt.args, all, tt.all)
+		}
+		if got := selects(expression); got != tt.matches {
+			t.Errorf("parseArgs(%q) selects %q, want %q", tt.args, got, tt.matches)
+		}
+	}
+	resetArgs()
+}
+
+// selects reports which test entries expr matches as the letters l, t and
+// d, or "" for no expression.
+func selects(expr predicate) string {
+	if expr == nil {
+		return ""
+	}
+	var b strings.Builder
+	for i, e := range []*walk.Entry{logFile, txtFile, logDir} {
+		if expr(e) {
+			b.WriteByte("ltd"[i])
+		}
+	}
+	return b.String()
+}
+
+func TestParseArgsErrors(t *testing.T) {
+	tests := [][]string{
+		{"-name"},
+		{"-bogus"},
+		{"--bogus"},
+		{"(", "-true"},
+		{"-true", ")"},
+		{"-o"},
+		{"-true", "-o"},
+		{"-name", "x", "-a", "-o", "-name", "y"},
+		{"(", "-name", "x", "-a", ")"},
+		{"-true", "x", "y"},
+		{"x", "-true", "y"},
+		{"-type", "q"},
+		{"-size", "1X"},
+		{"-perm", "u+q"},
+		{"-name", "["},
+		{"--vimgrep"},
+		{"--vimgrep", "-name", "*.go"},
+	}
+	for _, args := range tests {
+		resetArgs()
+		if _, err := parseArgs(args); err == nil {
+			t.Errorf("parseArgs(%q) succeeded", args)
+		}
+	}
+	resetArgs()
+}
+
+func TestParseMode(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want uint32
+	}{
+		{"644", 0644},
+		{"0755", 0755},
+		{"4755", 04755},
+		{"7777", 07777},
+		{"u+rwx", 0700},
+		{"u+rwx,g+rx", 0750},
+		{"ug+w", 0220},
+		{"a+r", 0444},
+		{"+x", 0111},
+		{"a+rwx,o-w", 0775},
+		{"u=rw", 0600},
+		{"a+rwx,g=r", 0747},
+		{"u+s", 04000},
+		{"g+s", 02000},
+		{"o+t", 01000},
+		{"+t", 01000},
+		{"u+t", 0},
+	}
+	for _, tt := range tests {
+		got, err := parseMode(tt.arg)
+		if err != nil || got != tt.want {
+			t.Errorf("parseMode(%q) = %#o, %v, want %#o", tt.arg, got, err, tt.want)
+		}
+	}
+	for _, arg := range []string{"", "8", "17777", "79", "u", "u+q", "z+r", "u+r,"} {
+		if _, err := parseMode(arg); err == nil {
+			t.Errorf("parseMode(%q) succeeded", arg)
+		}
+	}
+}
+
+func TestGlobRegexp(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		foldCase bool
+		name     string
+		want     bool
+	}{
+		{"*.log", false, "a.log", true},
+		{"*.log", false, "a.log.1", false},
+		{"*.log", false, "dir/a.log", true},
+		{"./a/*", false, "./a/b/c", true},
+		{"?.go", false, "a.go", true},
+		{"?.go", false, "ab.go", false},
+		{"[abc]x", false, "bx", true},
+		{"[abc]x", false, "dx", false},
+		{"[!abc]x", false, "bx", false},
+		{"[!abc]x", false, "dx", true},
+		{"[a-c]", false, "b", true},
+		{"[]x]", false, "]", true},
+		{"[]x]", false, "x", true},
+		{`\*`, false, "*", true},
+		{`\*`, false, "a", false},
+		{`a\`, false, `a\`, true},
+		{"a.b", false, "axb", false},
+		{"a+(b)", false, "a+(b)", true},
+		{"*.LOG", false, "a.log", false},
+		{"*.LOG", true, "a.log", true},
+		{"", false, "", true},
+		{"", false, "a", false},
+	}
+	for _, tt := range tests {
+		re, err := globRegexp(tt.pattern, tt.foldCase)
+		if err != nil {
+			t.Errorf("globRegexp(%q): %v", tt.pattern, err)
+			continue
+		}
+		if got := re.MatchString(tt.name); got != tt.want {
+			t.Errorf("globRegexp(%q, %v) matches %q = %v, want %v", tt.pattern, tt.foldCase, tt.name, got, tt.want)
+		}
+	}
+	for _, pattern := range []string{"[", "a[b", "[]"} {
+		if _, err := globRegexp(pattern, false); err == nil {
+			t.Errorf("globRegexp(%q) succeeded", pattern)
+		}
+	}
+}
+
+func TestSizeTest(t *testing.T) {
+	tests := []struct {
+		arg   string
+		entry walk.Entry
+		want  bool
+	}{
+		{"-1", walk.Entry{StatSize: 0}, true},
+		{"-1", walk.Entry{StatSize: 1}, false},
+		{"1", walk.Entry{StatSize: 1}, true},
+		{"1", walk.Entry{StatSize: 513}, false},
+		{"+1", walk.Entry{StatSize: 513}, true},
+		{"2", walk.Entry{StatSize: 1000}, true},
+		{"1k", walk.Entry{StatSize: 1024}, true},
+		{"1k", walk.Entry{StatSize: 1025}, false},
+		{"10c", walk.Entry{StatSize: 10}, true},
+		{"+1M", walk.Entry{StatSize: 1<<20 + 1}, true},
+		// a directory is tested by its own size, not what is below it
+		{"-1", walk.Entry{IsDir: true, StatSize: 4096, Size: 0}, false},
+		{"+1", walk.Entry{IsDir: true, StatSize: 0, Size: 1 << 20}, false},
+	}
+	for _, tt := range tests {
+		test, err := sizeTest(tt.arg)
+		if err != nil {
+			t.Errorf("sizeTest(%q): %v", tt.arg, err)
+			continue
+		}
+		if got := test(&tt.entry); got != tt.want {
+			t.Errorf("-size %s on %+v = %v, want %v", tt.arg, tt.entry, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/saurav-tiwari03/go-find/walk"
+)
+
+/* -------------------- find predicates -------------------- */
+
+// predicate reports whether a scanned entry matches a find expression.
+type predicate func(n *walk.Entry) bool
+
+// expression is the find expression given on the command line, or nil when
+// go-find only prints the tree.
+var expression predicate
+
+func findMode() bool {
+	return expression != nil
+}
+
+// findRoot is the directory argument as typed and scanRoot the path the
+// walk was given for it, which differ inside an archive.
+var findRoot, scanRoot = ".", "."
+
+// findPath returns the path of n the way find prints it: findRoot as typed,
+// then the path below it. Scanned paths are cleaned by filepath.Join, so
+// without this "./a/*" would never match a scan of "./a".
+func findPath(n *walk.Entry) string {
+	rel, err := filepath.Rel(scanRoot, n.Path)
+	if err != nil || rel == "." {
+		return findRoot
+	}
+	if strings.HasSuffix(findRoot, "/") || strings.HasSuffix(findRoot, string(filepath.Separator)) {
+		return findRoot + rel
+	}
+	return findRoot + string(filepath.Separator) + rel
+}
+
+// globRegexp translates a shell pattern into an anchored regular
+// expression. Unlike filepath.Match, '*' and '?' also match '/', which is
+// what find's -path expects.
+func globRegexp(pattern string, foldCase bool) (*regexp.Regexp, error) {
+	var b strings.Builder
+	if foldCase {
+		b.WriteString("(?i)")
+	}
+	b.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+			b.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid pattern %q: unterminated '['", pattern)
+			}
+			class := pattern[i+1 : i+1+end]
+			// "[]abc]" includes a literal ']'
+			if class == "" {
+				next := strings.IndexByte(pattern[i+2:], ']')
+				if next < 0 {
+					return nil, fmt.Errorf("invalid pattern %q: unterminated '['", pattern)
+				}
+				end = next + 1
+				class = pattern[i+1 : i+1+end]
+			}
+			if class[0] == '!' {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+
+	"github.com/fatih/color"
+	"github.com/saurav-tiwari03/go-find/walk"
+)
+
+/* -------------------- content search -------------------- */
+
+var (
+	// contentPattern is the --contains regular expression, or nil.
+	contentPattern *regexp.Regexp
+	// vimgrep prints hits as path:line:column:text for editor quickfix lists.
+	vimgrep bool
+
+	// hits holds the matching lines of every file that matched.
+	hits = map[*walk.Entry][]hit{}
+)
+
+// binarySniffLen is how much of a file is checked for NUL bytes before it
+// is treated as binary, the same heuristic git and grep use.
+const binarySniffLen = 8000
+
+// hit is one line of a file matching the content pattern. Column is the
+// 1-based byte offset of the first match on the line.
+type hit struct {
+	line   int
+	column int
+	text   string
+}
+
+func setContains(pattern string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	contentPattern = re
+	return nil
+}
+
+// containsTest reads a regular file and records its matching lines. Binary
+// and unreadable files never match.
+func containsTest(n *walk.Entry) bool {
+	if n.IsDir || !n.Mode.IsRegular() {
+		return false
+	}
+	f, err := openFile(n.Path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	r := bufio.NewReaderSize(f, binarySniffLen)
+	head, _ := r.Peek(binarySniffLen)
+	if bytes.IndexByte(head, 0) >= 0 {
+		return false
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		loc := contentPattern.FindIndex(scanner.Bytes())
+		if loc == nil {
+			continue
+		}
+		hits[n] = append(hits[n], hit{line: line, column: loc[0] + 1, text: scanner.Text()})
+	}
+	return len(hits[n]) > 0
+}
+
+// openFile opens a scanned file on the filesystem being walked.
+func openFile(path string) (fs.File, error) {
+	if walkOpts.FS != nil {
+		return walkOpts.FS.Open(path)
+	}
+	return os.Open(path)
+}
+
+// printHits renders the matching lines of a file nested under its tree
+// entry, with the match itself highlighted.
+func printHits(n *walk.Entry, prefix string) {
+	lineNo := color.New(color.FgGreen)
+	match := color.New(color.FgRed, color.Bold)
+	for _, h := range hits[n] {
+		text := contentPattern.ReplaceAllStringFunc(h.text, func(s string) string {
+			return match.Sprint(s)
+		})
+		fmt.Printf("%s    %s %s\n", prefix, lineNo.Sprintf("%4d:", h.line), text)
+	}
+}
+
+// matchedLines counts the hits of every matched file, whether or not the
+// output shows them.
+func matchedLines() int {
+	lines := 0
+	for _, h := range hits {
+		lines += len(h)
+	}
+	return lines
+}
+
+// printVimgrep writes every hit below dir in vim's errorformat, one line per
+// matching line.
+func printVimgrep(dir *walk.Entry) {
+	for _, n := range walk.Ordered(dir, walkOpts.Order) {
+		if n.IsDir {
+			printVimgrep(n)
+			continue
+		}
+		for _, h := range hits[n] {
+			fmt.Printf("%s:%d:%d:%s\n", n.Path, h.line, h.column, h.text)
+		}
+	}
+}
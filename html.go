@@ -0,0 +1,415 @@
+package main
+
+import (
+	"encoding/json"
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/saurav-tiwari03/go-find/walk"
+)
+
+/* -------------------- HTML report -------------------- */
+
+// htmlReport is the data of the report page. The tree is embedded in the
+// JSON output's layout and rendered by the page's own script, so the file
+// works offline and needs nothing besides itself.
+type htmlReport struct {
+	Title     string
+	Generated string
+	Size      string
+	Apparent  string
+	Files     string
+	Dirs      string
+	Links     int
+	Broken    int
+	Hidden    int
+	Tree      template.JS
+}
+
+func renderHTML(w io.Writer, res *walk.Result) error {
+	root := newJSONEntry(res.Root)
+	root.Children = jsonChildren(res.Root, 0)
+	// json.Marshal escapes <, > and &, so the data cannot end the script
+	data, err := json.Marshal(root)
+	if err != nil {
+		return err
+	}
+
+	report := htmlReport{
+		Title:     res.Root.Name,
+		Generated: time.Now().Format("2006-01-02 15:04 MST"),
+		Size:      humanSize(res.Stats.Size),
+		Files:     commas(res.Stats.Files),
+		Dirs:      commas(res.Stats.Dirs),
+		Links:     res.Stats.Links,
+		Broken:    res.Stats.BrokenLinks,
+		Hidden:    res.Stats.HiddenSkipped,
+		Tree:      template.JS(data),
+	}
+	if res.Stats.Apparent != res.Stats.Size {
+		report.Apparent = humanSize(res.Stats.Apparent)
+	}
+	return htmlTemplate.Execute(w, report)
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(htmlPage))
+
+// htmlPage uses system fonts only, so the report renders the same without
+// a network connection.
+const htmlPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <meta name="generator" content="go-find">
+    <title>go-find report: {{.Title}}</title>
+    <style>
+        * {
+            margin: 0;
+            padding: 0;
+            box-sizing: border-box;
+        }
+
+        body {
+            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
+            background: #0d0d0d;
+            color: #e0e0e0;
+            padding: 24px;
+            font-size: 14px;
+        }
+
+        h1 {
+            font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace;
+            font-size: 1.6rem;
+            color: #00ADD8;
+            word-break: break-all;
+        }
+
+        .meta {
+            color: #888;
+            margin: 4px 0 20px;
+        }
+
+        .summary {
+            display: flex;
+            flex-wrap: wrap;
+            gap: 12px;
+            margin-bottom: 20px;
+        }
+
+        .summary div {
+            background: #1a1a2e;
+            border-radius: 8px;
+            padding: 10px 16px;
+            color: #888;
+            font-size: 0.8rem;
+        }
+
+        .summary b {
+            display: block;
+            color: #fff;
+            font-size: 1.2rem;
+            font-weight: 600;
+        }
+
+        .controls {
+            display: flex;
+            gap: 8px;
+            margin-bottom: 12px;
+        }
+
+        input, button {
+            font: inherit;
+            color: #e0e0e0;
+            background: #1a1a2e;
+            border: 1px solid #333;
+            border-radius: 6px;
+            padding: 6px 10px;
+        }
+
+        input {
+            flex: 1;
+            max-width: 480px;
+        }
+
+        button {
+            cursor: pointer;
+        }
+
+        button:hover {
+            border-color: #00ADD8;
+        }
+
+        table {
+            width: 100%;
+            border-collapse: collapse;
+            font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace;
+            font-size: 0.85rem;
+        }
+
+        th {
+            text-align: left;
+            color: #5DC9E2;
+            border-bottom: 1px solid #333;
+            padding: 6px 8px;
+            cursor: pointer;
+            user-select: none;
+            white-space: nowrap;
+        }
+
+        td {
+            padding: 3px 8px;
+            white-space: nowrap;
+        }
+
+        .num {
+            text-align: right;
+        }
+
+        tbody tr:hover td {
+            background: #1a1a2e;
+        }
+
+        .toggle {
+            display: inline-block;
+            width: 1.2em;
+            color: #888;
+            cursor: pointer;
+        }
+
+        .directory {
+            color: #5DC9E2;
+        }
+
+        .link {
+            color: #c678dd;
+        }
+
+        .broken {
+            color: #e06c75;
+        }
+
+        .muted {
+            color: #666;
+        }
+
+        .bar {
+            display: inline-block;
+            height: 8px;
+            margin-right: 8px;
+            background: #00ADD8;
+            border-radius: 2px;
+            vertical-align: middle;
+        }
+
+        mark {
+            background: #665c00;
+            color: inherit;
+        }
+    </style>
+</head>
+<body>
+    <h1>{{.Title}}</h1>
+    <p class="meta">go-find report, generated {{.Generated}}</p>
+
+    <div class="summary">
+        <div><b>{{.Size}}</b>size</div>
+        {{- if .Apparent}}
+        <div><b>{{.Apparent}}</b>apparent size</div>
+        {{- end}}
+        <div><b>{{.Files}}</b>files</div>
+        <div><b>{{.Dirs}}</b>folders</div>
+        {{- if .Links}}
+        <div><b>{{.Links}}</b>links ({{.Broken}} broken)</div>
+        {{- end}}
+        {{- if .Hidden}}
+        <div><b>{{.Hidden}}</b>hidden skipped</div>
+        {{- end}}
+    </div>
+
+    <div class="controls">
+        <input id="filter" type="search" placeholder="Filter by name or path" autofocus>
+        <button id="expand">Expand all</button>
+        <button id="collapse">Collapse all</button>
+    </div>
+
+    <table>
+        <thead>
+            <tr>
+                <th data-key="name">Name</th>
+                <th data-key="size" class="num">Size</th>
+                <th data-key="files" class="num">Files</th>
+                <th data-key="mtime">Modified</th>
+                <th data-key="mode">Mode</th>
+            </tr>
+        </thead>
+        <tbody id="rows"></tbody>
+    </table>
+
+    <script>
+        const root = {{.Tree}};
+        const rows = document.getElementById('rows');
+        const open = new Set([root]);
+        let sortKey = '', descending = false, query = '';
+
+        function humanSize(bytes) {
+            if (bytes < 1024) return bytes + ' B';
+            let div = 1024, exp = 0;
+            for (let n = bytes / 1024; n >= 1024; n /= 1024) {
+                div *= 1024;
+                exp++;
+            }
+            return (bytes / div).toFixed(1) + ' ' + 'KMGTPE'[exp] + 'B';
+        }
+
+        function value(e, key) {
+            switch (key) {
+                case 'size': return e.size;
+                case 'files': return e.files || 0;
+                case 'mtime': return e.mtime || '';
+                case 'mode': return e.mode || '';
+            }
+            return e.name.toLowerCase();
+        }
+
+        // without a column picked, children keep the order go-find gave them
+        function ordered(children) {
+            if (!sortKey) return children;
+            return children.slice().sort((a, b) => {
+                const x = value(a, sortKey), y = value(b, sortKey);
+                const c = x < y ? -1 : x > y ? 1 : 0;
+                return descending ? -c : c;
+            });
+        }
+
+        // mark flags the entries that match the filter and those leading to one
+        function mark(e) {
+            e.hit = query !== '' && e.path.toLowerCase().includes(query);
+            e.shown = query === '' || e.hit;
+            for (const child of e.children || []) {
+                if (mark(child)) e.shown = true;
+            }
+            return e.shown;
+        }
+
+        function highlight(cell, text) {
+            const at = query ? text.toLowerCase().indexOf(query) : -1;
+            if (at < 0) {
+                cell.append(text);
+                return;
+            }
+            const m = document.createElement('mark');
+            m.textContent = text.slice(at, at + query.length);
+            cell.append(text.slice(0, at), m, text.slice(at + query.length));
+        }
+
+        function cell(tr, text, cls) {
+            const td = tr.insertCell();
+            td.textContent = text;
+            if (cls) td.className = cls;
+            return td;
+        }
+
+        function addRows(out, e, depth) {
+            for (const child of ordered(e.children || [])) {
+                if (!child.shown) continue;
+                const tr = document.createElement('tr');
+                const name = tr.insertCell();
+                name.style.paddingLeft = (8 + depth * 18) + 'px';
+
+                const toggle = document.createElement('span');
+                toggle.className = 'toggle';
+                const expandable = child.children && child.children.length > 0;
+                const expanded = expandable && (query !== '' || open.has(child));
+                if (expandable) {
+                    toggle.textContent = expanded ? '▾' : '▸';
+                    toggle.onclick = () => {
+                        open.has(child) ? open.delete(child) : open.add(child);
+                        render();
+                    };
+                }
+                name.append(toggle);
+
+                const label = document.createElement('span');
+                label.className = child.broken ? 'broken' : child.type;
+                label.title = child.path;
+                highlight(label, child.name + (child.type === 'directory' ? '/' : ''));
+                name.append(label);
+                if (child.target) name.append(' -> ' + child.target);
+                const notes = [];
+                if (child.broken) notes.push('broken');
+                if (child.hard_link) notes.push('hard link');
+                if (child.skipped) notes.push(child.skipped);
+                if (child.mount) notes.push('mount: ' + child.mount);
+                if (notes.length) {
+                    const note = document.createElement('span');
+                    note.className = 'muted';
+                    note.textContent = ' [' + notes.join(', ') + ']';
+                    name.append(note);
+                }
+
+                const size = tr.insertCell();
+                size.className = 'num';
+                const bar = document.createElement('span');
+                bar.className = 'bar';
+                bar.style.width = (root.size ? Math.round(60 * child.size / root.size) : 0) + 'px';
+                size.append(bar, humanSize(child.size));
+
+                cell(tr, child.files === undefined ? '' : child.files.toLocaleString(), 'num');
+                cell(tr, child.mtime ? child.mtime.slice(0, 16).replace('T', ' ') : '', 'muted');
+                cell(tr, child.mode || '', 'muted');
+                out.append(tr);
+
+                if (expanded) addRows(out, child, depth + 1);
+            }
+        }
+
+        function render() {
+            const out = document.createDocumentFragment();
+            addRows(out, root, 0);
+            rows.replaceChildren(out);
+            for (const th of document.querySelectorAll('th')) {
+                const arrow = th.dataset.key === sortKey ? (descending ? ' ▾' : ' ▴') : '';
+                th.textContent = th.textContent.replace(/ [▾▴]$/, '') + arrow;
+            }
+        }
+
+        function walk(e, fn) {
+            fn(e);
+            for (const child of e.children || []) walk(child, fn);
+        }
+
+        document.getElementById('filter').oninput = (ev) => {
+            query = ev.target.value.trim().toLowerCase();
+            mark(root);
+            render();
+        };
+        document.getElementById('expand').onclick = () => {
+            walk(root, (e) => open.add(e));
+            render();
+        };
+        document.getElementById('collapse').onclick = () => {
+            open.clear();
+            open.add(root);
+            render();
+        };
+        for (const th of document.querySelectorAll('th')) {
+            th.onclick = () => {
+                const key = th.dataset.key;
+                if (sortKey === key) {
+                    descending = !descending;
+                } else {
+                    sortKey = key;
+                    descending = key === 'size' || key === 'files' || key === 'mtime';
+                }
+                render();
+            };
+        }
+
+        mark(root);
+        render();
+    </script>
+</body>
+</html>
+`
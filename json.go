@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/saurav-tiwari03/go-find/walk"
+)
+
+/* -------------------- JSON output -------------------- */
+
+// jsonSchema identifies the layout of the JSON documents. It changes only
+// when a field is removed or changes meaning; new fields may be added
+// within a version.
+const jsonSchema = "go-find/v1"
+
+type jsonDocument struct {
+	Schema  string      `json:"schema"`
+	Root    *jsonEntry  `json:"root"`
+	Summary jsonSummary `json:"summary"`
+}
+
+type jsonEntry struct {
+	Name  string     `json:"name"`
+	Path  string     `json:"path"`
+	Type  string     `json:"type"`
+	Size  *int64     `json:"size,omitempty"`
+	Mode  string     `json:"mode,omitempty"`
+	MTime *time.Time `json:"mtime,omitempty"`
+
+	// directories only
+	Files    *int   `json:"files,omitempty"`
+	Dirs     *int   `json:"directories,omitempty"`
+	Apparent *int64 `json:"apparent_size,omitempty"`
+
+	Target   string       `json:"target,omitempty"`
+	Broken   bool         `json:"broken,omitempty"`
+	HardLink bool         `json:"hard_link,omitempty"`
+	Mount    string       `json:"mount,omitempty"`
+	Skipped  string       `json:"skipped,omitempty"`
+	Matched  bool         `json:"matched,omitempty"`
+	Lines    []jsonHit    `json:"lines,omitempty"`
+	Children []*jsonEntry `json:"children,omitempty"`
+}
+
+type jsonHit struct {
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Text   string `json:"text"`
+}
+
+type jsonSummary struct {
+	Size          int64 `json:"size"`
+	Apparent      int64 `json:"apparent_size"`
+	Files         int   `json:"files"`
+	Dirs          int   `json:"directories"`
+	Links         int   `json:"links"`
+	BrokenLinks   int   `json:"broken_links"`
+	HiddenSkipped int   `json:"hidden_skipped"`
+	Lines         *int  `json:"matched_lines,omitempty"`
+}
+
+func renderJSON(w io.Writer, res *walk.Result) error {
+	doc := jsonDocument{
+		Schema:  jsonSchema,
+		Root:    newJSONEntry(res.Root),
+		Summary: newJSONSummary(res.Stats),
+	}
+	doc.Root.Children = jsonChildren(res.Root, 0)
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// jsonChildren converts the children of dir, which is depth levels below
+// the root, in the --sort order.
+func jsonChildren(dir *walk.Entry, depth int) []*jsonEntry {
+	if !expanded(dir, depth) {
+		return nil
+	}
+	var children []*jsonEntry
+	for _, e := range walk.Ordered(dir, walkOpts.Order) {
+		child := newJSONEntry(e)
+		child.Children = jsonChildren(e, depth+1)
+		children = append(children, child)
+	}
+	return children
+}
+
+// newJSONEntry converts e without its children.
+func newJSONEntry(e *walk.Entry) *jsonEntry {
+	j := &jsonEntry{
+		Name:     e.Name,
+		Path:     e.Path,
+		Type:     entryType(e),
+		Size:     &e.Size,
+		Target:   e.LinkTarget,
+		Broken:   e.Broken,
+		HardLink: e.ExtraLink,
+		Mount:    e.Mount,
+		Skipped:  e.Placeholder,
+		Matched:  e.Matched,
+	}
+	if e.Placeholder != "" {
+		return j
+	}
+	j.Mode = fmt.Sprintf("%04o", unixPerm(e.Mode))
+	mtime := e.ModTime.UTC()
+	j.MTime = &mtime
+	if e.IsDir {
+		j.Files, j.Dirs, j.Apparent = &e.Files, &e.Dirs, &e.Apparent
+	}
+	for _, h := range hits[e] {
+		j.Lines = append(j.Lines, jsonHit{h.line, h.column, h.text})
+	}
+	return j
+}
+
+func newJSONSummary(stats walk.Stats) jsonSummary {
+	s := jsonSummary{
+		Size:          stats.Size,
+		Apparent:      stats.Apparent,
+		Files:         stats.Files,
+		Dirs:          stats.Dirs,
+		Links:         stats.Links,
+		BrokenLinks:   stats.BrokenLinks,
+		HiddenSkipped: stats.HiddenSkipped,
+	}
+	if contentPattern != nil {
+		lines := matchedLines()
+		s.Lines = &lines
+	}
+	return s
+}
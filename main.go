@@ -1,17 +1,24 @@
 package main
 
 import (
+	"archive/zip"
+	"bufio"
+	"context"
 	"fmt"
 	"os"
-	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/fatih/color"
+	"github.com/saurav-tiwari03/go-find/walk"
 )
 
 var (
-	totalSize    int64
-	totalFiles   int
-	totalFolders int
+	// showCounts adds the number of files and folders below each directory
+	// next to its size.
+	showCounts bool
+	// maxDepth is the deepest level rendered by -L, or 0 for no limit.
+	maxDepth int
 )
 
 func banner() {
@@ -46,36 +53,55 @@ func humanSize(bytes int64) string {
 	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
-func sizeCalc(path string) int64 {
-	info, err := os.Stat(path)
-	if err != nil {
-		return 0
+// commas formats n with thousands separators.
+func commas(n int) string {
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0 && s[i-1] != '-'; i -= 3 {
+		s = s[:i] + "," + s[i:]
 	}
-	if !info.IsDir() {
-		totalSize += info.Size()
-		return info.Size()
+	return s
+}
+
+func setDepth(value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return fmt.Errorf("invalid level %q", value)
 	}
-	return 0
+	maxDepth = n
+	return nil
 }
 
-/* -------------------- tree logic -------------------- */
+// linkNote is the " -> target" suffix shown after a link's name.
+func linkNote(e *walk.Entry) string {
+	if e.LinkTarget == "" {
+		return ""
+	}
+	return " -> " + e.LinkTarget
+}
 
-func tree(path string, prefix string) {
-	entries, err := os.ReadDir(path)
-	if err != nil {
-		return
+// mountNote is the annotation shown after a mount point's name.
+func mountNote(e *walk.Entry) string {
+	if e.Mount == "" {
+		return ""
 	}
+	return color.YellowString(" [mount: %s]", e.Mount)
+}
 
-	// directories first, files later
-	var dirs, files []os.DirEntry
-	for _, e := range entries {
-		if e.IsDir() {
-			dirs = append(dirs, e)
-		} else {
-			files = append(files, e)
-		}
+// sizeNote is the annotation printed after an entry's name.
+func sizeNote(n *walk.Entry) string {
+	if n.ExtraLink {
+		return fmt.Sprintf(" (%s, hard link)", humanSize(n.Size))
+	}
+	if n.IsDir && showCounts {
+		return fmt.Sprintf(" (%s, %d files, %d folders)", humanSize(n.Size), n.Files, n.Dirs)
 	}
-	entries = append(dirs, files...)
+	return fmt.Sprintf(" (%s)", humanSize(n.Size))
+}
+
+/* -------------------- tree logic -------------------- */
+
+func tree(dir *walk.Entry, prefix string, depth int) {
+	entries := walk.Ordered(dir, walkOpts.Order)
 
 	for i, entry := range entries {
 		isLast := i == len(entries)-1
@@ -87,18 +113,39 @@ func tree(path string, prefix string) {
 			nextPrefix = prefix + "    "
 		}
 
-		fullPath := filepath.Join(path, entry.Name())
-		icon := iconDecide(entry.IsDir())
+		icon := iconDecide(entry.IsDir)
+		if entry.LinkTarget != "" && !entry.IsDir {
+			icon = "🔗"
+		}
 
-		if entry.IsDir() {
-			color.Blue("%s%s%s %s/", prefix, connector, icon, entry.Name())
-			totalFolders++
-			tree(fullPath, nextPrefix)
+		if entry.Placeholder != "" {
+			color.HiBlack("%s%s%s %s/%s [%s]", prefix, connector, icon, entry.Name, linkNote(entry), entry.Placeholder)
+			continue
+		}
+
+		if entry.IsDir {
+			// in find mode, unmatched directories are only shown dimmed as
+			// the path leading to a match
+			name := color.BlueString("%s%s%s %s/%s", prefix, connector, icon, entry.Name, linkNote(entry))
+			if findMode() && !entry.Matched {
+				name = color.HiBlackString("%s%s%s %s/%s", prefix, connector, icon, entry.Name, linkNote(entry))
+			}
+			// below -L, a directory is collapsed into a one-line summary
+			// of everything inside it
+			if maxDepth > 0 && depth >= maxDepth && len(entry.Children) > 0 {
+				fmt.Println(name + color.HiBlackString(" … %s files, %s", commas(entry.Files), humanSize(entry.Size)) + mountNote(entry))
+				continue
+			}
+			fmt.Println(name + color.HiBlackString(sizeNote(entry)) + mountNote(entry))
+			tree(entry, nextPrefix, depth+1)
 		} else {
-			size := sizeCalc(fullPath)
-			color.White("%s%s%s %s", prefix, connector, icon, entry.Name())
-			color.HiBlack(" (%s)", humanSize(size))
-			totalFiles++
+			if entry.Broken {
+				fmt.Println(color.RedString("%s%s%s %s%s [broken]", prefix, connector, icon, entry.Name, linkNote(entry)))
+			} else {
+				fmt.Println(color.WhiteString("%s%s%s %s%s", prefix, connector, icon, entry.Name, linkNote(entry)) +
+					color.HiBlackString(sizeNote(entry)))
+			}
+			printHits(entry, nextPrefix)
 		}
 	}
 }
@@ -112,38 +159,148 @@ func header() {
 	color.HiBlack("────────────────────────────────────────")
 }
 
+func usage() {
+	fmt.Println("Usage: go-find [DIR|ARCHIVE.zip] [EXPRESSION]")
+	fmt.Println("       go-find readme-sync [--check] FILE")
+	fmt.Println()
+	fmt.Println("Tests:     -name -iname -path -ipath PATTERN, -type [fdlpsbc],")
+	fmt.Println("           -size [+-]N[cwbkMG], -mtime [+-]DAYS, -mmin [+-]MINUTES,")
+	fmt.Println("           -perm [-/]MODE, -user NAME, -group NAME, -empty, -true, -false")
+	fmt.Println("Operators: ( EXPR ), ! EXPR, -not EXPR, EXPR -a EXPR, EXPR -o EXPR")
+	fmt.Println()
+	fmt.Println("Options:   -L LEVEL           collapse directories below LEVEL into a summary")
+	fmt.Println("           --sort KEY         order entries by name, version, size, mtime, ext or count")
+	fmt.Println("           --reverse          reverse the sort order")
+	fmt.Println("           --mixed            sort directories and files together")
+	fmt.Println("           --no-ignore        include files ignored by git")
+	fmt.Println("           -a, --all          show hidden files and directories")
+	fmt.Println("           --hidden-shallow   show hidden entries without descending into them")
+	fmt.Println("           --follow           descend into symlinked directories")
+	fmt.Println("           --one-file-system  do not descend into other filesystems")
+	fmt.Println("           --jobs N           read up to N directories in parallel")
+	fmt.Println("           --exclude GLOB     skip matching entries (repeatable)")
+	fmt.Println("           --include GLOB     show only files matching GLOB (repeatable)")
+	fmt.Println("           --show-excluded    list skipped directories as [excluded]")
+	fmt.Println("           --counts           show file and folder counts of each directory")
+	fmt.Println("           --top N            list the N largest files and directories")
+	fmt.Println("           --contains REGEXP  show only files with matching lines")
+	fmt.Println("           --vimgrep          print content matches as path:line:col:text")
+	fmt.Println("           --output FORMAT    print the scan as " + formatNames())
+	fmt.Println("           --output FILE.EXT  write the scan to FILE in the format named by EXT")
+	fmt.Println("           --comments FILE    annotate the markdown tree with FILE's # comments")
+	fmt.Println("           --columns LIST     csv and tsv columns: path, name, type, size, mtime,")
+	fmt.Println("                              mode, owner, group, extension, depth, hash")
+	fmt.Println("           --dot-files        include files in the dot graph")
+	fmt.Println("           --color-by KEY     color the svg treemap by type or age")
+}
+
 /* -------------------- main -------------------- */
 
 func main() {
-	header()
+	if len(os.Args) > 1 && os.Args[1] == "readme-sync" {
+		os.Exit(readmeSync(os.Args[2:]))
+	}
 
-	// Get target directory from command-line argument or use current directory
-	targetDir := "."
-	if len(os.Args) > 1 {
-		targetDir = os.Args[1]
+	// Get options, target directory and find expression from command-line arguments
+	targetDir, err := parseArgs(os.Args[1:])
+	if err != nil {
+		header()
+		color.Red("❌ Error: %v", err)
+		usage()
+		os.Exit(2)
+	}
+
+	// A zip archive is walked like a directory; a directory that happens to
+	// be named *.zip is still walked as one
+	walkRoot := targetDir
+	if info, err := os.Stat(targetDir); err == nil && info.Mode().IsRegular() &&
+		strings.HasSuffix(strings.ToLower(targetDir), ".zip") {
+		archive, err := zip.OpenReader(targetDir)
+		if err != nil {
+			color.Red("❌ Error: %v", err)
+			os.Exit(1)
+		}
+		defer archive.Close()
+		walkOpts.FS, walkRoot, scanRoot = archive, ".", "."
 	}
 
-	// Validate directory exists
-	info, err := os.Stat(targetDir)
+	// Scan the directory, which also validates that it exists
+	walkOpts.Match = expression
+	out := bufio.NewWriter(os.Stdout)
+	var file *os.File
+	if outputFile != "" {
+		file, err = os.Create(outputFile)
+		if err != nil {
+			color.Red("❌ Error: %v", err)
+			os.Exit(1)
+		}
+		out = bufio.NewWriter(file)
+	}
+	if stream, ok := streams[outputFormat]; ok {
+		walkOpts.Stream = stream(out)
+	}
+	res, err := walk.New(walkOpts).Walk(context.Background(), walkRoot)
 	if err != nil {
 		color.Red("❌ Error: %v", err)
 		os.Exit(1)
 	}
-	if !info.IsDir() {
-		color.Red("❌ Error: %s is not a directory", targetDir)
-		os.Exit(1)
+	root := res.Root
+	root.Name = targetDir // rather than "." inside an archive
+
+	// quickfix output is for editors and carries no decoration
+	if vimgrep {
+		printVimgrep(root)
+		return
+	}
+	if outputFormat != "" {
+		err := render(out, res)
+		if err == nil && file != nil {
+			err = file.Close()
+		}
+		if err != nil {
+			color.Red("❌ Error: %v", err)
+			os.Exit(1)
+		}
+		if file != nil {
+			fmt.Printf("%s written\n", outputFile)
+		}
+		return
 	}
 
+	header()
 	color.HiBlack("Scanning directory: %s\n", targetDir)
-	fmt.Println(targetDir)
 
-	tree(targetDir, "")
+	if topN > 0 {
+		top(root)
+	} else {
+		fmt.Println(targetDir + color.HiBlackString(sizeNote(root)))
+		tree(root, "", 1)
+	}
 
 	color.HiBlack("\n────────────────────────────────────────")
 	color.Cyan("Summary")
-	fmt.Printf("  Size     : %s\n", humanSize(totalSize))
-	fmt.Printf("  Files    : %d\n", totalFiles)
-	fmt.Printf("  Folders  : %d\n", totalFolders)
+	stats := res.Stats
+	if findMode() {
+		fmt.Printf("  Size     : %s matched\n", humanSize(stats.Size))
+		fmt.Printf("  Files    : %d matched\n", stats.Files)
+		fmt.Printf("  Folders  : %d matched\n", stats.Dirs)
+		if contentPattern != nil {
+			fmt.Printf("  Lines    : %d matched\n", matchedLines())
+		}
+	} else {
+		fmt.Printf("  Size     : %s\n", humanSize(stats.Size))
+		fmt.Printf("  Files    : %d\n", stats.Files)
+		fmt.Printf("  Folders  : %d\n", stats.Dirs)
+	}
+	if stats.Apparent != stats.Size {
+		fmt.Printf("  Apparent : %s (hard links counted once in Size)\n", humanSize(stats.Apparent))
+	}
+	if stats.Links > 0 {
+		fmt.Printf("  Links    : %d (%d broken)\n", stats.Links, stats.BrokenLinks)
+	}
+	if stats.HiddenSkipped > 0 {
+		fmt.Printf("  Hidden   : %d skipped\n", stats.HiddenSkipped)
+	}
 
 	color.HiBlack("\nDone ✔")
 }
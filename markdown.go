@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/saurav-tiwari03/go-find/walk"
+)
+
+/* -------------------- Markdown output -------------------- */
+
+// comments maps paths relative to the root, slash-separated, to the note
+// printed after them by the Markdown output.
+var comments map[string]string
+
+// commentLine is a line of a --comments file: a path, then "# note".
+var commentLine = regexp.MustCompile(`^(\S.*?)\s+#\s?(.*)$`)
+
+// setComments loads a --comments file, which is written like the tree it
+// annotates:
+//
+//	main.go       # CLI application & tree rendering
+//	walk/         # importable walker library
+//	walk/walk.go  # Walker, Options, Result & Visitor
+//
+// Blank lines and lines starting with # are skipped.
+func setComments(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	comments = map[string]string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		m := commentLine.FindStringSubmatch(line)
+		if m == nil {
+			return fmt.Errorf("%s: no comment in %q", file, line)
+		}
+		comments[path.Clean(m[1])] = m[2]
+	}
+	return nil
+}
+
+func renderMarkdown(w io.Writer, res *walk.Result) error {
+	markdownTree(w, res.Root, rootLabel(res.Root))
+	return nil
+}
+
+// markdownLine is one line of the plain tree and its comment, if any.
+type markdownLine struct {
+	text    string
+	comment string
+}
+
+// markdownTree writes root, named label, as a fenced plain-text tree, with
+// the comments lined up in one column after the longest commented line.
+func markdownTree(w io.Writer, root *walk.Entry, label string) {
+	lines := []markdownLine{{label, comments["."]}}
+	lines = plainTree(lines, root, root, "", 0)
+
+	width := 0
+	for _, l := range lines {
+		if n := utf8.RuneCountInString(l.text); l.comment != "" && n > width {
+			width = n
+		}
+	}
+	fmt.Fprintln(w, "```")
+	for _, l := range lines {
+		if l.comment == "" {
+			fmt.Fprintln(w, l.text)
+			continue
+		}
+		pad := width - utf8.RuneCountInString(l.text) + 2
+		fmt.Fprintf(w, "%s%s# %s\n", l.text, strings.Repeat(" ", pad), l.comment)
+	}
+	fmt.Fprintln(w, "```")
+}
+
+// plainTree appends the lines of the entries below dir, which is depth
+// levels below root, drawn like the tree view without colors or icons.
+func plainTree(lines []markdownLine, root, dir *walk.Entry, prefix string, depth int) []markdownLine {
+	if !expanded(dir, depth) {
+		return lines
+	}
+	entries := walk.Ordered(dir, walkOpts.Order)
+	for i, e := range entries {
+		connector, nextPrefix := "├── ", prefix+"│   "
+		if i == len(entries)-1 {
+			connector, nextPrefix = "└── ", prefix+"    "
+		}
+		name := e.Name
+		if e.IsDir {
+			name += "/"
+		}
+		rel, _ := filepath.Rel(root.Path, e.Path)
+		lines = append(lines, markdownLine{prefix + connector + name + linkNote(e), comments[filepath.ToSlash(rel)]})
+		if e.IsDir {
+			lines = plainTree(lines, root, e, nextPrefix, depth+1)
+		}
+	}
+	return lines
+}
+
+// rootLabel names the root the way a project is usually shown, by its
+// directory name rather than the path it was scanned by.
+func rootLabel(root *walk.Entry) string {
+	name := root.Name
+	if abs, err := filepath.Abs(name); err == nil && walkOpts.FS == nil {
+		name = abs
+	}
+	return filepath.Base(name) + "/"
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"path"
+	"path/filepath"
+
+	"github.com/saurav-tiwari03/go-find/walk"
+)
+
+/* -------------------- NDJSON output -------------------- */
+
+// ndjsonEntry is the record of one entry: the fields of the JSON output
+// without children, placed by depth and parent path instead.
+type ndjsonEntry struct {
+	Record string `json:"record"`
+	Depth  int    `json:"depth"`
+	Parent string `json:"parent,omitempty"`
+	*jsonEntry
+}
+
+type ndjsonSummary struct {
+	Record string `json:"record"`
+	Schema string `json:"schema"`
+	jsonSummary
+}
+
+// streamNDJSON returns the walk.Options.Stream callback that writes a
+// record to w for every entry while the walk is running. Directory totals
+// are not known yet, so directories carry no size and only the summary
+// has the totals.
+func streamNDJSON(w io.Writer) func(e *walk.Entry, depth int) {
+	enc := json.NewEncoder(w)
+	return func(e *walk.Entry, depth int) {
+		if maxDepth > 0 && depth > maxDepth {
+			return
+		}
+		rec := ndjsonEntry{Record: "entry", Depth: depth, jsonEntry: newJSONEntry(e)}
+		if e.IsDir {
+			rec.Size, rec.Apparent, rec.Files, rec.Dirs = nil, nil, nil, nil
+		}
+		if depth > 0 {
+			rec.Parent = parentPath(e)
+		}
+		enc.Encode(rec)
+	}
+}
+
+// parentPath is the path of the directory holding e, written like the
+// path of that directory's own record.
+func parentPath(e *walk.Entry) string {
+	if walkOpts.FS != nil {
+		return path.Dir(e.Path)
+	}
+	return filepath.Dir(e.Path)
+}
+
+// renderNDJSON ends the stream with the summary record. Write errors during
+// the walk stay with w and surface here.
+func renderNDJSON(w io.Writer, res *walk.Result) error {
+	return json.NewEncoder(w).Encode(ndjsonSummary{
+		Record:      "summary",
+		Schema:      jsonSchema,
+		jsonSummary: newJSONSummary(res.Stats),
+	})
+}
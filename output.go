@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/saurav-tiwari03/go-find/walk"
+)
+
+/* -------------------- output formats -------------------- */
+
+var (
+	// outputFormat is the --output format, or "" for the decorated tree.
+	outputFormat string
+	// outputFile is where the output goes when --output names a file
+	// rather than a format, or "" for stdout.
+	outputFile string
+)
+
+// renderer writes a finished scan in one of the --output formats. Unlike
+// the tree view it prints no banner, colors or emoji.
+type renderer func(w io.Writer, res *walk.Result) error
+
+var renderers = map[string]renderer{
+	"json":     renderJSON,
+	"ndjson":   renderNDJSON,
+	"xml":      renderXML,
+	"html":     renderHTML,
+	"markdown": renderMarkdown,
+	"csv":      renderCSV,
+	"tsv":      renderTSV,
+	"dot":      renderDOT,
+	"svg":      renderTreemap,
+}
+
+// streams hold the formats written while the walk is running. Each returns
+// the walk.Options.Stream callback, and its renderer finishes the output.
+var streams = map[string]func(w io.Writer) func(e *walk.Entry, depth int){
+	"ndjson": streamNDJSON,
+}
+
+// setOutput takes a format name, or a file name whose extension is one, as
+// in --output treemap.svg.
+func setOutput(value string) error {
+	if _, ok := renderers[value]; ok {
+		outputFormat, outputFile = value, ""
+		return nil
+	}
+	ext := strings.TrimPrefix(filepath.Ext(value), ".")
+	if _, ok := renderers[ext]; !ok || ext == "" {
+		return fmt.Errorf("unknown format %q (want %s, or a file with one as its extension)", value, formatNames())
+	}
+	outputFormat, outputFile = ext, value
+	return nil
+}
+
+func formatNames() string {
+	var names []string
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// render writes res to w in the --output format. w is the writer given to
+// the format's stream, if it has one.
+func render(w *bufio.Writer, res *walk.Result) error {
+	if err := renderers[outputFormat](w, res); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// entryType names the kind of an entry the way tree -J and tree -X do.
+func entryType(e *walk.Entry) string {
+	switch {
+	case e.LinkTarget != "":
+		return "link"
+	case e.IsDir:
+		return "directory"
+	}
+	return "file"
+}
+
+// expanded reports whether the children of dir, which is depth levels
+// below the root, are rendered. Past -L they are left out but still
+// counted in the directory's totals.
+func expanded(dir *walk.Entry, depth int) bool {
+	return dir.Placeholder == "" && (maxDepth == 0 || depth < maxDepth)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/saurav-tiwari03/go-find/walk"
+)
+
+/* -------------------- readme-sync -------------------- */
+
+// A tree block of a Markdown file lies between these markers. The start
+// marker may carry go-find arguments, e.g. <!-- go-find:start -L 2 -->.
+const (
+	syncStart = "<!-- go-find:start"
+	syncEnd   = "<!-- go-find:end -->"
+)
+
+// readmeSync runs "go-find readme-sync [--check] FILE", which renders the
+// tree blocks of FILE afresh, and returns the exit status. With --check
+// the file is left alone and the status is 1 when a block is stale.
+func readmeSync(args []string) int {
+	check, file := false, ""
+	for _, arg := range args {
+		switch {
+		case arg == "--check":
+			check = true
+		case file == "" && !strings.HasPrefix(arg, "-"):
+			file = arg
+		default:
+			color.Red("❌ Error: unexpected argument %s", arg)
+			fmt.Println("Usage: go-find readme-sync [--check] FILE")
+			return 2
+		}
+	}
+	if file == "" {
+		color.Red("❌ Error: missing file")
+		fmt.Println("Usage: go-find readme-sync [--check] FILE")
+		return 2
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		color.Red("❌ Error: %v", err)
+		return 1
+	}
+	// paths in the markers are relative to the file
+	if err := os.Chdir(filepath.Dir(file)); err != nil {
+		color.Red("❌ Error: %v", err)
+		return 1
+	}
+	synced, err := syncBlocks(string(data))
+	if err != nil {
+		color.Red("❌ Error: %s: %v", file, err)
+		return 1
+	}
+
+	switch {
+	case synced == string(data):
+		fmt.Printf("%s is up to date\n", file)
+	case check:
+		color.Red("❌ %s is out of date, run go-find readme-sync %s", file, file)
+		return 1
+	default:
+		if err := os.WriteFile(filepath.Base(file), []byte(synced), 0o644); err != nil {
+			color.Red("❌ Error: %v", err)
+			return 1
+		}
+		fmt.Printf("%s updated\n", file)
+	}
+	return 0
+}
+
+// syncBlocks returns text with the content of every tree block replaced by
+// a fresh rendering. Markers inside fenced code blocks are examples and are
+// left alone.
+func syncBlocks(text string) (string, error) {
+	var out strings.Builder
+	lines := strings.SplitAfter(text, "\n")
+	blocks := 0
+	fence := ""
+	for i := 0; i < len(lines); i++ {
+		out.WriteString(lines[i])
+		marker := strings.TrimSpace(lines[i])
+		switch {
+		case fence != "":
+			if closesFence(marker, fence) {
+				fence = ""
+			}
+			continue
+		case opensFence(marker) != "":
+			fence = opensFence(marker)
+			continue
+		case !strings.HasPrefix(marker, syncStart):
+			continue
+		}
+		args, ok := strings.CutSuffix(strings.TrimPrefix(marker, syncStart), "-->")
+		if !ok {
+			return "", fmt.Errorf("line %d: unterminated marker", i+1)
+		}
+
+		end := i + 1
+		for end < len(lines) && strings.TrimSpace(lines[end]) != syncEnd {
+			end++
+		}
+		if end == len(lines) {
+			return "", fmt.Errorf("line %d: no %s after the start marker", i+1, syncEnd)
+		}
+		block, err := renderBlock(strings.Fields(args))
+		if err != nil {
+			return "", fmt.Errorf("line %d: %v", i+1, err)
+		}
+		if !strings.HasSuffix(lines[i], "\n") {
+			out.WriteString("\n")
+		}
+		out.WriteString(block)
+		out.WriteString(lines[end])
+		i = end
+		blocks++
+	}
+	if blocks == 0 {
+		return "", fmt.Errorf("no %s --> marker found", syncStart)
+	}
+	return out.String(), nil
+}
+
+// opensFence returns the ``` or ~~~ run that opens a fenced code block on
+// line, or "" when line does not open one.
+func opensFence(line string) string {
+	if !strings.HasPrefix(line, "```") && !strings.HasPrefix(line, "~~~") {
+		return ""
+	}
+	n := len(line) - len(strings.TrimLeft(line, line[:1]))
+	return line[:n]
+}
+
+// closesFence reports whether line ends the block opened by fence: a run
+// of the same character at least as long, with nothing after it.
+func closesFence(line, fence string) bool {
+	return strings.HasPrefix(line, fence) && strings.Trim(line, fence[:1]) == ""
+}
+
+// renderBlock renders the Markdown tree for the arguments of a marker,
+// which are read like go-find's own command line. The root is labelled
+// with the directory as written in the marker, so the block does not
+// change with the name of the checkout.
+func renderBlock(args []string) (string, error) {
+	resetArgs()
+	targetDir, err := parseArgs(args)
+	if err != nil {
+		return "", err
+	}
+	walkOpts.Match = expression
+	res, err := walk.New(walkOpts).Walk(context.Background(), targetDir)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	markdownTree(&b, res.Root, strings.TrimSuffix(filepath.ToSlash(filepath.Clean(targetDir)), "/")+"/")
+	return b.String(), nil
+}
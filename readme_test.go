@@ -0,0 +1,124 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// syncDir creates a small project named name in a temporary directory and
+// makes it the working directory, as readmeSync does for the file's
+// directory.
+func syncDir(t *testing.T, name string) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), name)
+	for file, data := range map[string]string{"a.txt": "a", "b/c.txt": "c"} {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(dir)
+	t.Cleanup(resetArgs)
+}
+
+const syncedTree = "```\n./\n├── b/\n│   └── c.txt\n└── a.txt\n```\n"
+
+func TestSyncBlocks(t *testing.T) {
+	syncDir(t, "proj")
+	tests := []struct {
+		name, text, want string
+	}{
+		{
+			"stale block",
+			"# Layout\n<!-- go-find:start -->\nold\n<!-- go-find:end -->\nafter\n",
+			"# Layout\n<!-- go-find:start -->\n" + syncedTree + "<!-- go-find:end -->\nafter\n",
+		},
+		{
+			"empty block",
+			"<!-- go-find:start -->\n<!-- go-find:end -->\n",
+			"<!-- go-find:start -->\n" + syncedTree + "<!-- go-find:end -->\n",
+		},
+		{
+			"arguments",
+			"<!-- go-find:start -L 1 b -->\n<!-- go-find:end -->",
+			"<!-- go-find:start -L 1 b -->\n```\nb/\n└── c.txt\n```\n<!-- go-find:end -->",
+		},
+		{
+			"backtick fence",
+			"```markdown\n<!-- go-find:start missing -->\n<!-- go-find:end -->\n```\n<!-- go-find:start -->\n<!-- go-find:end -->\n",
+			"```markdown\n<!-- go-find:start missing -->\n<!-- go-find:end -->\n```\n<!-- go-find:start -->\n" + syncedTree + "<!-- go-find:end -->\n",
+		},
+		{
+			"tilde fence",
+			"~~~\n<!-- go-find:start missing -->\n~~~\n<!-- go-find:start -->\n<!-- go-find:end -->\n",
+			"~~~\n<!-- go-find:start missing -->\n~~~\n<!-- go-find:start -->\n" + syncedTree + "<!-- go-find:end -->\n",
+		},
+		{
+			"longer fence",
+			"````\n```\n<!-- go-find:start missing -->\n```\n````\n<!-- go-find:start -->\n<!-- go-find:end -->\n",
+			"````\n```\n<!-- go-find:start missing -->\n```\n````\n<!-- go-find:start -->\n" + syncedTree + "<!-- go-find:end -->\n",
+		},
+		{
+			"other fence character",
+			"```\n~~~\n<!-- go-find:start missing -->\n```\n<!-- go-find:start -->\n<!-- go-find:end -->\n",
+			"```\n~~~\n<!-- go-find:start missing -->\n```\n<!-- go-find:start -->\n" + syncedTree + "<!-- go-find:end -->\n",
+		},
+	}
+	for _, tt := range tests {
+		got, err := syncBlocks(tt.text)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s:\n got %q\nwant %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSyncBlocksErrors(t *testing.T) {
+	syncDir(t, "proj")
+	tests := []struct {
+		name, text, err string
+	}{
+		{"no marker", "# Layout\n", "no <!-- go-find:start --> marker found"},
+		{"only fenced markers", "```\n<!-- go-find:start -->\n<!-- go-find:end -->\n```\n", "no <!-- go-find:start --> marker found"},
+		{"unclosed fence", "```\n<!-- go-find:start -->\n<!-- go-find:end -->\n", "no <!-- go-find:start --> marker found"},
+		{"missing end marker", "x\n<!-- go-find:start -->\nold\n", "line 2: no <!-- go-find:end --> after the start marker"},
+		{"unterminated marker", "<!-- go-find:start -L 2\n<!-- go-find:end -->\n", "line 1: unterminated marker"},
+		{"bad arguments", "<!-- go-find:start --bogus -->\n<!-- go-find:end -->\n", "line 1: unknown option --bogus"},
+		{"missing directory", "<!-- go-find:start missing -->\n<!-- go-find:end -->\n", "line 1: "},
+	}
+	for _, tt := range tests {
+		_, err := syncBlocks(tt.text)
+		if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+			t.Errorf("%s: error %v, want %q", tt.name, err, tt.err)
+		}
+	}
+}
+
+// TestSyncBlocksStable checks what --check relies on: a synced file stays
+// the same when synced again, also in a checkout with another name.
+func TestSyncBlocksStable(t *testing.T) {
+	text := "# Layout\n<!-- go-find:start -L 2 -->\n<!-- go-find:end -->\n"
+	syncDir(t, "proj")
+	synced, err := syncBlocks(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"proj", "proj-clone"} {
+		syncDir(t, name)
+		again, err := syncBlocks(synced)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if again != synced {
+			t.Errorf("syncing again in %s changed the file:\n got %q\nwant %q", name, again, synced)
+		}
+	}
+}
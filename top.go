@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/saurav-tiwari03/go-find/walk"
+)
+
+/* -------------------- top report -------------------- */
+
+// topN is the number of entries listed by --top, or 0 for the tree view.
+var topN int
+
+const barWidth = 20
+
+func setTop(value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return fmt.Errorf("invalid count %q", value)
+	}
+	topN = n
+	return nil
+}
+
+// collect appends every file and every directory below dir to the given
+// lists. Symbolic links and hard links after the first take no space of
+// their own and are left out of the files, so a file is ranked once and
+// the shares add up to at most the total.
+func collect(dir *walk.Entry, files, dirs *[]*walk.Entry) {
+	for _, n := range dir.Children {
+		switch {
+		case n.Placeholder != "":
+		case n.IsDir:
+			*dirs = append(*dirs, n)
+			collect(n, files, dirs)
+		case n.LinkTarget == "" && !n.ExtraLink:
+			*files = append(*files, n)
+		}
+	}
+}
+
+// top prints the largest files and directories below root, each with its
+// share of the total size.
+func top(root *walk.Entry) {
+	var files, dirs []*walk.Entry
+	collect(root, &files, &dirs)
+
+	color.Cyan("Largest files")
+	ranking(files, root.Size)
+	fmt.Println()
+	color.Cyan("Largest directories")
+	ranking(dirs, root.Size)
+}
+
+func ranking(entries []*walk.Entry, total int64) {
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Size > entries[j].Size })
+	if len(entries) > topN {
+		entries = entries[:topN]
+	}
+	if len(entries) == 0 {
+		color.HiBlack("  (none)")
+		return
+	}
+
+	for i, n := range entries {
+		share := 0.0
+		if total > 0 {
+			share = float64(n.Size) / float64(total)
+		}
+		filled := int(share*barWidth + 0.5)
+		bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+
+		name := color.WhiteString(n.Path)
+		if n.IsDir {
+			name = color.BlueString(n.Path + "/")
+		}
+		fmt.Printf("  %3d. %10s %6.1f%%  %s  %s\n", i+1, humanSize(n.Size), share*100, color.CyanString(bar), name)
+	}
+}
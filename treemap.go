@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/saurav-tiwari03/go-find/walk"
+)
+
+/* -------------------- SVG treemap -------------------- */
+
+// treemapColor is what the file rectangles are colored by: "type" or "age".
+var treemapColor = "type"
+
+const (
+	treemapWidth  = 1280
+	treemapHeight = 800
+	// treemapHeader is the strip at the top of a directory that holds its
+	// name, and treemapPad the margin around its children.
+	treemapHeader = 14
+	treemapPad    = 2
+)
+
+func setTreemapColor(value string) error {
+	if value != "type" && value != "age" {
+		return fmt.Errorf("unknown coloring %q (want type or age)", value)
+	}
+	treemapColor = value
+	return nil
+}
+
+// rect is an area of the treemap in SVG user units.
+type rect struct {
+	x, y, w, h float64
+}
+
+// renderTreemap writes a squarified treemap of the tree as an SVG image:
+// every file is a rectangle sized by its bytes, nested in the rectangles of
+// its directories, with the path and size as a tooltip.
+func renderTreemap(w io.Writer, res *walk.Result) error {
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="10">`+"\n",
+		treemapWidth, treemapHeight, treemapWidth, treemapHeight)
+	fmt.Fprintln(w, `<rect width="100%" height="100%" fill="#0d0d0d"/>`)
+	t := treemap{w: w, now: time.Now()}
+	t.dir(res.Root, rootLabel(res.Root), rect{0, 0, treemapWidth, treemapHeight}, 0)
+	fmt.Fprintln(w, "</svg>")
+	return nil
+}
+
+type treemap struct {
+	w   io.Writer
+	now time.Time
+}
+
+// dir draws the directory e, which is depth levels below the root, into r
+// and its children inside it. Past -L, or when there is no room left, the
+// directory is drawn as a single block.
+func (t treemap) dir(e *walk.Entry, name string, r rect, depth int) {
+	inner := rect{r.x + treemapPad, r.y + treemapHeader, r.w - 2*treemapPad, r.h - treemapHeader - treemapPad}
+	if !expanded(e, depth) || inner.w < 4 || inner.h < 4 {
+		t.box(e, r, "#3a3a5e", name)
+		return
+	}
+	t.box(e, r, "#1a1a2e", "")
+	if r.w > 30 {
+		fmt.Fprintf(t.w, `<text x="%.1f" y="%.1f" fill="#5DC9E2">%s</text>`+"\n",
+			r.x+3, r.y+11, xmlAttr(fitLabel(name, r.w-6)))
+	}
+
+	// hard links after the first take no room, as they take no space
+	var children []*walk.Entry
+	var sizes []float64
+	for _, c := range walk.Ordered(e, walk.Order{Key: "size", Mixed: true}) {
+		if c.Placeholder == "" && !c.ExtraLink && c.Size > 0 {
+			children = append(children, c)
+			sizes = append(sizes, float64(c.Size))
+		}
+	}
+	for i, cr := range squarify(sizes, inner) {
+		c := children[i]
+		if c.IsDir {
+			t.dir(c, c.Name+"/", cr, depth+1)
+		} else {
+			t.box(c, cr, t.fill(c), c.Name)
+		}
+	}
+}
+
+// box draws one rectangle with a tooltip and, when it fits, a label.
+func (t treemap) box(e *walk.Entry, r rect, fill, label string) {
+	if r.w < 0.5 || r.h < 0.5 {
+		return
+	}
+	fmt.Fprintf(t.w, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s" stroke="#0d0d0d" stroke-width="0.5"><title>%s (%s)</title></rect>`+"\n",
+		r.x, r.y, r.w, r.h, fill, xmlAttr(e.Path), humanSize(e.Size))
+	if label != "" && r.w > 30 && r.h > 14 {
+		fmt.Fprintf(t.w, `<text x="%.1f" y="%.1f" fill="#ffffff" pointer-events="none">%s</text>`+"\n",
+			r.x+3, r.y+11, xmlAttr(fitLabel(label, r.w-6)))
+	}
+}
+
+// fill is the color of a file: a hue per extension, or with --color-by age
+// green for fresh files through red for those untouched for two years.
+func (t treemap) fill(e *walk.Entry) string {
+	if treemapColor == "age" {
+		days := math.Max(t.now.Sub(e.ModTime).Hours()/24, 0)
+		age := math.Min(math.Log1p(days)/math.Log1p(730), 1)
+		return fmt.Sprintf("hsl(%.0f, 60%%, 45%%)", 120*(1-age))
+	}
+	ext := strings.ToLower(filepath.Ext(e.Name))
+	if ext == "" {
+		return "hsl(0, 0%, 45%)"
+	}
+	h := fnv.New32a()
+	h.Write([]byte(ext))
+	return fmt.Sprintf("hsl(%d, 55%%, 45%%)", h.Sum32()%360)
+}
+
+// fitLabel shortens s to roughly fit width, at about 6 units a character.
+func fitLabel(s string, width float64) string {
+	runes := []rune(s)
+	max := int(width / 6)
+	if len(runes) <= max {
+		return s
+	}
+	if max < 2 {
+		return ""
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+// squarify divides r among sizes, which are sorted largest first, in
+// proportion to them, keeping the rectangles as close to squares as it can
+// (Bruls, Huizing and van Wijk, "Squarified Treemaps").
+func squarify(sizes []float64, r rect) []rect {
+	total := 0.0
+	for _, s := range sizes {
+		total += s
+	}
+	if total == 0 {
+		return nil
+	}
+	areas := make([]float64, len(sizes))
+	for i, s := range sizes {
+		areas[i] = s * r.w * r.h / total
+	}
+
+	out := make([]rect, 0, len(areas))
+	for len(areas) > 0 {
+		// fill a row along the shorter side while that makes its worst
+		// aspect ratio better
+		side := math.Min(r.w, r.h)
+		n := 1
+		for n < len(areas) && worstRatio(areas[:n+1], side) <= worstRatio(areas[:n], side) {
+			n++
+		}
+		row := 0.0
+		for _, a := range areas[:n] {
+			row += a
+		}
+
+		if r.w >= r.h {
+			width := row / r.h
+			y := r.y
+			for _, a := range areas[:n] {
+				out = append(out, rect{r.x, y, width, a / width})
+				y += a / width
+			}
+			r.x, r.w = r.x+width, r.w-width
+		} else {
+			height := row / r.w
+			x := r.x
+			for _, a := range areas[:n] {
+				out = append(out, rect{x, r.y, a / height, height})
+				x += a / height
+			}
+			r.y, r.h = r.y+height, r.h-height
+		}
+		areas = areas[n:]
+	}
+	return out
+}
+
+// worstRatio is the largest aspect ratio among the rectangles of a row of
+// the given areas laid along side.
+func worstRatio(row []float64, side float64) float64 {
+	sum, largest, smallest := 0.0, 0.0, math.Inf(1)
+	for _, a := range row {
+		sum += a
+		largest = math.Max(largest, a)
+		smallest = math.Min(smallest, a)
+	}
+	return math.Max(side*side*largest/(sum*sum), sum*sum/(side*side*smallest))
+}
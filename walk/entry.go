@@ -0,0 +1,71 @@
+package walk
+
+import (
+	"io/fs"
+	"time"
+)
+
+/* -------------------- entries -------------------- */
+
+// Entry is a single file or directory of a walk. Directories carry their
+// children and the size and entry counts of everything below them.
+type Entry struct {
+	Name  string
+	Path  string
+	IsDir bool
+	// Size is a file's size or, for a directory, the total of everything
+	// below it with hard-linked files counted once. Apparent counts every
+	// link.
+	Size     int64
+	Apparent int64
+	// StatSize is the size stat reports for the entry itself, which for a
+	// directory is its own inode rather than its contents. It is known as
+	// soon as the entry is scanned, before Size is totalled.
+	StatSize int64
+	// Files and Dirs count everything below a directory.
+	Files int
+	Dirs  int
+
+	Mode    fs.FileMode
+	ModTime time.Time
+	// UID and GID are the numeric owner ids, empty where the platform has
+	// none.
+	UID string
+	GID string
+
+	// LinkTarget is what a symbolic link points to; Broken is set when
+	// that does not exist.
+	LinkTarget string
+	Broken     bool
+	// ExtraLink is set on every hard link to a file after the first, whose
+	// bytes are not counted again.
+	ExtraLink bool
+	// Mount describes the filesystem of a directory that is a mount point.
+	Mount string
+	// Placeholder marks a directory that is listed but was not scanned,
+	// and says why: "excluded", "hidden", "loop" or "mount: ...".
+	Placeholder string
+	// Matched is set on the entries Options.Match selected.
+	Matched bool
+
+	Children []*Entry
+
+	inode string
+	dev   uint64
+}
+
+// setInfo records the metadata of an entry.
+func (e *Entry) setInfo(info fs.FileInfo) {
+	e.Mode = info.Mode()
+	e.StatSize = info.Size()
+	e.ModTime = info.ModTime()
+	e.UID, e.GID = fileOwner(info)
+	e.dev, _ = fileDevice(info)
+	if !info.IsDir() {
+		e.inode = hardlinkKey(info)
+	}
+}
+
+func placeholder(name, path, reason string) *Entry {
+	return &Entry{Name: name, Path: path, IsDir: true, Placeholder: reason}
+}
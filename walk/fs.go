@@ -0,0 +1,57 @@
+package walk
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+/* -------------------- filesystems -------------------- */
+
+// ReadLinkFS is implemented by filesystems with symbolic links. Walks of
+// other filesystems never report links.
+type ReadLinkFS interface {
+	fs.FS
+	// ReadLink returns the destination of the named symbolic link.
+	ReadLink(name string) (string, error)
+	// Lstat describes the named file without following a final symbolic
+	// link.
+	Lstat(name string) (fs.FileInfo, error)
+}
+
+// OS is the operating system's filesystem, walked when Options.FS is nil.
+// Unlike os.DirFS it takes native paths as they are, absolute or relative,
+// so entries keep the paths the caller gave.
+var OS ReadLinkFS = osFS{}
+
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error)          { return os.Open(name) }
+func (osFS) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+func (osFS) Stat(name string) (fs.FileInfo, error)      { return os.Stat(name) }
+func (osFS) Lstat(name string) (fs.FileInfo, error)     { return os.Lstat(name) }
+func (osFS) ReadLink(name string) (string, error)       { return os.Readlink(name) }
+
+// isOS reports whether the walk is on the operating system's filesystem,
+// the only one with repositories, devices and mount points to look at.
+func (r *run) isOS() bool {
+	return r.fsys == OS
+}
+
+// join builds the path of name inside dir: native on the OS filesystem and
+// slash-separated on any other.
+func (r *run) join(dir, name string) string {
+	if r.isOS() {
+		return filepath.Join(dir, name)
+	}
+	return path.Join(dir, name)
+}
+
+// clean is the shortest form of a path, as join gives for children.
+func (r *run) clean(name string) string {
+	if r.isOS() {
+		return filepath.Clean(name)
+	}
+	return path.Clean(name)
+}
@@ -0,0 +1,16 @@
+package walk
+
+/* -------------------- hard links -------------------- */
+
+// seenInode reports whether the file identified by key was met before and
+// records it. Files with a single link have no key and are never repeats.
+func (r *run) seenInode(key string) bool {
+	if key == "" {
+		return false
+	}
+	if r.seen[key] {
+		return true
+	}
+	r.seen[key] = true
+	return false
+}
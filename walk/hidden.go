@@ -0,0 +1,10 @@
+package walk
+
+import "strings"
+
+/* -------------------- hidden entries -------------------- */
+
+// isHidden reports whether name is a dotfile or dot-directory.
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".") && name != "." && name != ".."
+}
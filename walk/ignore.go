@@ -0,0 +1,358 @@
+package walk
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+/* -------------------- ignore rules -------------------- */
+
+// IgnoreFileName is go-find's own per-directory ignore file. It uses the
+// gitignore syntax and applies inside and outside repositories.
+const IgnoreFileName = ".gofindignore"
+
+// ignoreRule is one pattern line of a gitignore file.
+type ignoreRule struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreFile holds the rules of one gitignore-style file. Patterns are
+// matched against paths relative to base, with prefix prepended for files
+// that live above the scanned directory.
+type ignoreFile struct {
+	base   string
+	prefix string
+	rules  []ignoreRule
+}
+
+// ignoreStack lists the ignore files in effect for a directory from the
+// lowest to the highest priority: the global excludes file, the repository's
+// info/exclude, then every .gitignore from the repository root downwards.
+type ignoreStack []*ignoreFile
+
+// ignored reports whether path is excluded. As in git, the last matching
+// rule decides, so deeper files and later lines override earlier ones.
+func (s ignoreStack) ignored(path string, isDir bool) bool {
+	ignored := false
+	for _, f := range s {
+		if matched, negated := f.match(path, isDir); matched {
+			ignored = !negated
+		}
+	}
+	return ignored
+}
+
+// match applies the rules of f to path. It reports whether any rule
+// matched and whether the last one to match was a "!" pattern.
+func (f *ignoreFile) match(path string, isDir bool) (matched, negated bool) {
+	if f == nil {
+		return false, false
+	}
+	rel, ok := relativeTo(f.base, path)
+	if !ok {
+		return false, false
+	}
+	rel = f.prefix + filepath.ToSlash(rel)
+	for _, r := range f.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		if r.re.MatchString(rel) {
+			matched, negated = true, r.negate
+		}
+	}
+	return matched, negated
+}
+
+// filtered reports whether Exclude drops path or, for files, Include
+// leaves it out.
+func (r *run) filtered(path string, isDir bool) bool {
+	if matched, negated := r.excludes.match(path, isDir); matched && !negated {
+		return true
+	}
+	if r.includes == nil || isDir {
+		return false
+	}
+	matched, negated := r.includes.match(path, isDir)
+	return !matched || negated
+}
+
+// compileFilters turns Exclude or Include patterns into rules relative to
+// the scanned directory.
+func compileFilters(dir string, patterns []string) *ignoreFile {
+	if len(patterns) == 0 {
+		return nil
+	}
+	f := &ignoreFile{base: filepath.Clean(dir)}
+	for _, p := range patterns {
+		if rule, ok := parseIgnoreLine(p); ok {
+			f.rules = append(f.rules, rule)
+		}
+	}
+	return f
+}
+
+// push returns the stack with the ignore file at path added on top, or the
+// stack unchanged when there is no such file. The result never shares its
+// tail with s, so sibling directories cannot see each other's rules.
+func (s ignoreStack) push(fsys fs.FS, base, path string) ignoreStack {
+	f := loadIgnoreFile(fsys, filepath.Clean(base), path)
+	if f == nil {
+		return s
+	}
+	return append(s[:len(s):len(s)], f)
+}
+
+// relativeTo returns path relative to base. Scanned paths are always built
+// by joining onto their parent, so a prefix check is enough. Paths of an
+// fs.FS use '/' whatever the platform's separator. A root such as "/" or
+// `C:\` already ends in a separator.
+func relativeTo(base, path string) (string, bool) {
+	if base == "." {
+		return path, path != "."
+	}
+	for _, sep := range []string{string(filepath.Separator), "/"} {
+		prefix := base
+		if !strings.HasSuffix(prefix, sep) {
+			prefix += sep
+		}
+		if rel, ok := strings.CutPrefix(path, prefix); ok && rel != "" {
+			return rel, true
+		}
+	}
+	return "", false
+}
+
+func loadIgnoreFile(fsys fs.FS, base, path string) *ignoreFile {
+	file, err := fsys.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	f := &ignoreFile{base: base}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreLine(scanner.Text()); ok {
+			f.rules = append(f.rules, rule)
+		}
+	}
+	if len(f.rules) == 0 {
+		return nil
+	}
+	return f
+}
+
+// parseIgnoreLine reads one line of a gitignore file following
+// gitignore(5): comments, negation, escaped characters, directory-only
+// patterns and anchoring on a slash.
+func parseIgnoreLine(line string) (ignoreRule, bool) {
+	line = strings.TrimSuffix(line, "\r")
+
+	// trailing spaces are dropped unless escaped
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	if line == "" || line[0] == '#' {
+		return ignoreRule{}, false
+	}
+
+	var rule ignoreRule
+	if line[0] == '!' {
+		rule.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	// a slash anywhere but the end anchors the pattern to the file's
+	// directory; otherwise it matches at any depth
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	expr := "^"
+	if !anchored {
+		expr += "(?:.*/)?"
+	}
+	re, err := regexp.Compile(expr + ignoreRegexp(line) + "$")
+	if err != nil {
+		return ignoreRule{}, false
+	}
+	rule.re = re
+	return rule, true
+}
+
+// ignoreRegexp translates a gitignore pattern into a regular expression.
+// '*' and '?' stop at '/', while a "**" path component spans any number of
+// directories.
+func ignoreRegexp(pattern string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '*' && strings.HasPrefix(pattern[i:], "**") &&
+			(i == 0 || pattern[i-1] == '/') &&
+			(i+2 == len(pattern) || pattern[i+2] == '/'):
+			if i+2 == len(pattern) {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("(?:.*/)?")
+				i += 2
+			}
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end <= 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if class[0] == '!' {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+/* -------------------- repository lookup -------------------- */
+
+// gitIgnores returns the git ignore rules that apply above dir and sets
+// gitRepo when dir is inside a repository. The stack is empty outside a
+// repository, when Gitignore is off or when the walk is not on the OS
+// filesystem. The ignore files of dir itself are picked up by scan.
+func (r *run) gitIgnores(dir string) ignoreStack {
+	stack := ignoreStack{}
+	if !r.Gitignore || !r.isOS() {
+		return stack
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return stack
+	}
+	root, gitDir := findRepo(abs)
+	if root == "" {
+		return stack
+	}
+	r.gitRepo = true
+
+	if global := globalExcludesFile(); global != "" {
+		stack = stack.push(OS, root, global)
+	}
+	stack = stack.push(OS, root, filepath.Join(gitDir, "info", "exclude"))
+
+	// .gitignore files between the repository root and dir
+	rel, _ := filepath.Rel(root, abs)
+	parent := root
+	if rel != "." {
+		for _, part := range strings.Split(rel, string(filepath.Separator)) {
+			stack = stack.push(OS, parent, filepath.Join(parent, ".gitignore"))
+			parent = filepath.Join(parent, part)
+		}
+	}
+
+	// rebase the rules onto dir as given, so they match the scanned paths
+	for _, f := range stack {
+		if r, _ := filepath.Rel(f.base, abs); r != "." {
+			f.prefix = filepath.ToSlash(r) + "/"
+		}
+		f.base = filepath.Clean(dir)
+	}
+	return stack
+}
+
+// findRepo walks up from dir to the enclosing work tree and returns its
+// root and git directory.
+func findRepo(dir string) (root, gitDir string) {
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		if info, err := os.Stat(dotGit); err == nil {
+			if info.IsDir() {
+				return dir, dotGit
+			}
+			// worktrees and submodules use a "gitdir: PATH" file
+			if data, err := os.ReadFile(dotGit); err == nil {
+				if path, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: "); ok {
+					if !filepath.IsAbs(path) {
+						path = filepath.Join(dir, path)
+					}
+					return dir, path
+				}
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ""
+		}
+		dir = parent
+	}
+}
+
+// globalExcludesFile returns core.excludesFile from the user's git config,
+// falling back to git's default location.
+func globalExcludesFile() string {
+	home, _ := os.UserHomeDir()
+	config := os.Getenv("XDG_CONFIG_HOME")
+	if config == "" && home != "" {
+		config = filepath.Join(home, ".config")
+	}
+
+	for _, path := range []string{filepath.Join(home, ".gitconfig"), filepath.Join(config, "git", "config")} {
+		if file := configExcludesFile(path); file != "" {
+			if rest, ok := strings.CutPrefix(file, "~/"); ok {
+				file = filepath.Join(home, rest)
+			}
+			return file
+		}
+	}
+	if config == "" {
+		return ""
+	}
+	return filepath.Join(config, "git", "ignore")
+}
+
+// configExcludesFile reads core.excludesFile from a git config file.
+func configExcludesFile(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	section := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			section = strings.ToLower(strings.Trim(line, "[] "))
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if ok && section == "core" && strings.EqualFold(strings.TrimSpace(key), "excludesfile") {
+			return strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	return ""
+}
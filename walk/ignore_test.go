@@ -0,0 +1,150 @@
+package walk
+
+import "testing"
+
+func TestParseIgnoreLineSkips(t *testing.T) {
+	for _, line := range []string{"", "   ", "# comment", "!", "/", "//"} {
+		if _, ok := parseIgnoreLine(line); ok {
+			t.Errorf("parseIgnoreLine(%q) returned a rule", line)
+		}
+	}
+}
+
+func TestIgnored(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		path  string
+		isDir bool
+		want  bool
+	}{
+		// unanchored patterns match at any depth
+		{"glob", []string{"*.log"}, "a.log", false, true},
+		{"glob in subdirectory", []string{"*.log"}, "x/y/a.log", false, true},
+		{"glob suffix", []string{"*.log"}, "a.logx", false, false},
+		{"name", []string{"build"}, "src/build", true, true},
+
+		// a leading or inner slash anchors to the ignore file's directory
+		{"leading slash", []string{"/build"}, "build", true, true},
+		{"leading slash deeper", []string{"/build"}, "src/build", true, false},
+		{"inner slash", []string{"doc/*.txt"}, "doc/a.txt", false, true},
+		{"inner slash deeper", []string{"doc/*.txt"}, "x/doc/a.txt", false, false},
+		{"star stops at slash", []string{"doc/*.txt"}, "doc/sub/a.txt", false, false},
+		{"question mark", []string{"a?c"}, "abc", false, true},
+		{"question mark stops at slash", []string{"a?c"}, "a/c", false, false},
+
+		// "**"
+		{"leading **", []string{"**/foo"}, "foo", false, true},
+		{"leading ** deeper", []string{"**/foo"}, "a/b/foo", false, true},
+		{"inner ** none", []string{"a/**/b"}, "a/b", false, true},
+		{"inner ** several", []string{"a/**/b"}, "a/x/y/b", false, true},
+		{"inner ** not a component", []string{"a/**/b"}, "ab", false, false},
+		{"trailing **", []string{"abc/**"}, "abc/x/y", false, true},
+		{"trailing ** itself", []string{"abc/**"}, "abc", true, false},
+		{"** inside a name", []string{"a**b"}, "a/b", false, false},
+
+		// directory-only patterns
+		{"dir only on dir", []string{"build/"}, "build", true, true},
+		{"dir only on file", []string{"build/"}, "build", false, false},
+		{"dir only anchored", []string{"/out/"}, "src/out", true, false},
+
+		// negation: the last matching rule wins
+		{"negated", []string{"*.log", "!keep.log"}, "keep.log", false, false},
+		{"negation not matching", []string{"*.log", "!keep.log"}, "a.log", false, true},
+		{"negation overridden", []string{"!keep.log", "*.log"}, "keep.log", false, true},
+		{"negation alone", []string{"!keep.log"}, "keep.log", false, false},
+
+		// escapes and character classes
+		{"escaped hash", []string{`\#file`}, "#file", false, true},
+		{"escaped bang", []string{`\!important`}, "!important", false, true},
+		{"escaped star", []string{`\*`}, "*", false, true},
+		{"escaped star is literal", []string{`\*`}, "a", false, false},
+		{"escaped trailing space", []string{`foo\ `}, "foo ", false, true},
+		{"trailing spaces dropped", []string{"foo   "}, "foo", false, true},
+		{"class", []string{"[ab]x"}, "bx", false, true},
+		{"negated class", []string{"[!a]x"}, "ax", false, false},
+		{"negated class other", []string{"[!a]x"}, "bx", false, true},
+		{"unclosed class", []string{"a["}, "a[", false, true},
+		{"dot is literal", []string{"a.b"}, "axb", false, false},
+	}
+	for _, tt := range tests {
+		f := &ignoreFile{base: "."}
+		for _, line := range tt.lines {
+			if rule, ok := parseIgnoreLine(line); ok {
+				f.rules = append(f.rules, rule)
+			}
+		}
+		if got := (ignoreStack{f}).ignored(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("%s: %q ignored(%q, dir=%v) = %v, want %v", tt.name, tt.lines, tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestIgnoreStackPrefix(t *testing.T) {
+	// rules of a .gitignore above the scanned directory see paths with the
+	// directories in between prepended
+	rule, _ := parseIgnoreLine("/sub/*.tmp")
+	f := &ignoreFile{base: "root", prefix: "sub/", rules: []ignoreRule{rule}}
+	if !(ignoreStack{f}).ignored("root/a.tmp", false) {
+		t.Error("root/a.tmp not ignored by /sub/*.tmp one level up")
+	}
+	if (ignoreStack{f}).ignored("other/a.tmp", false) {
+		t.Error("other/a.tmp ignored though it is outside the base")
+	}
+}
+
+func TestIgnoreRegexp(t *testing.T) {
+	tests := []struct {
+		pattern, want string
+	}{
+		{"*.go", `[^/]*\.go`},
+		{"a?", `a[^/]`},
+		{"**", `.*`},
+		{"**/x", `(?:.*/)?x`},
+		{"a/**/b", `a/(?:.*/)?b`},
+		{"a/**", `a/.*`},
+		{"a**", `a[^/]*[^/]*`},
+		{`\[x`, `\[x`},
+		{"[!0-9]", `[^0-9]`},
+		{"[", `\[`},
+	}
+	for _, tt := range tests {
+		if got := ignoreRegexp(tt.pattern); got != tt.want {
+			t.Errorf("ignoreRegexp(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestRelativeTo(t *testing.T) {
+	tests := []struct {
+		base, path, want string
+		ok               bool
+	}{
+		{".", "a/b", "a/b", true},
+		{".", ".", ".", false},
+		{"a", "a/b", "b", true},
+		{"a", "ab/c", "", false},
+		{".", ".", ".", false},
+		{"/", "/usr", "usr", true},
+		{"/", "/usr/lib", "usr/lib", true},
+		{".", ".", ".", false},
+		{"/srv/app", "/srv/app/x", "x", true},
+		{"/srv/app", "/srv/application", "", false},
+	}
+	for _, tt := range tests {
+		rel, ok := relativeTo(tt.base, tt.path)
+		if rel != tt.want || ok != tt.ok {
+			t.Errorf("relativeTo(%q, %q) = %q, %v, want %q, %v", tt.base, tt.path, rel, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFiltersAtFilesystemRoot(t *testing.T) {
+	// the root "/" already ends in a separator
+	excludes := compileFilters("/", []string{"usr", "/tmp/"})
+	for path, want := range map[string]bool{"/usr": true, "/srv/usr": true, "/tmp": true, "/var/tmp": false, "/etc": false} {
+		if matched, _ := excludes.match(path, true); matched != want {
+			t.Errorf("exclude at / matches %s = %v, want %v", path, matched, want)
+		}
+	}
+}
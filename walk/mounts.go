@@ -0,0 +1,77 @@
+package walk
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+/* -------------------- mount points -------------------- */
+
+// mountInfoPath is the Linux mount table. Elsewhere it does not exist and
+// mount points are described without their filesystem.
+const mountInfoPath = "/proc/self/mountinfo"
+
+// describeMount returns the filesystem type and device mounted at path.
+// The mount table is read once per walk, on first use.
+func (r *run) describeMount(path string) string {
+	r.mountsOnce.Do(func() { r.mounts = loadMounts() })
+	if abs, err := filepath.Abs(path); err == nil {
+		if desc, ok := r.mounts[abs]; ok {
+			return desc
+		}
+	}
+	return "mount point"
+}
+
+// loadMounts parses mountinfo lines of the form
+//
+//	36 35 98:0 /mnt1 /mnt/parent rw,noatime master:1 - ext3 /dev/root rw
+//
+// where the mount point is the fifth field and the filesystem type and
+// source follow the "-" separator.
+func loadMounts() map[string]string {
+	mounts := map[string]string{}
+	file, err := os.Open(mountInfoPath)
+	if err != nil {
+		return mounts
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		sep := -1
+		for i, f := range fields {
+			if f == "-" {
+				sep = i
+				break
+			}
+		}
+		if len(fields) < 5 || sep < 0 || sep+2 >= len(fields) {
+			continue
+		}
+		// later lines are mounted on top of earlier ones
+		mounts[unescapeMount(fields[4])] = fields[sep+1] + " " + unescapeMount(fields[sep+2])
+	}
+	return mounts
+}
+
+// unescapeMount decodes the octal escapes (\040 for a space and so on)
+// the kernel uses in mountinfo.
+func unescapeMount(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if c, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,22 @@
+package walk
+
+import "sync"
+
+/* -------------------- parallel scan -------------------- */
+
+// spawn runs fn on a new goroutine when a job slot is free and inline
+// otherwise. Falling back to the caller instead of waiting keeps the
+// recursion from deadlocking once every slot is taken.
+func (r *run) spawn(wg *sync.WaitGroup, fn func()) {
+	select {
+	case r.slots <- struct{}{}:
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-r.slots }()
+			fn()
+		}()
+	default:
+		fn()
+	}
+}
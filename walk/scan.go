@@ -0,0 +1,207 @@
+package walk
+
+import (
+	"io/fs"
+	"sync"
+	"sync/atomic"
+)
+
+/* -------------------- scan -------------------- */
+
+// scan reads the directory dir, described by info, and everything below
+// it, filling in dir. depth counts the levels below the root.
+//
+// Subdirectories are scanned concurrently when a job slot is free, each
+// filling in its own entry, so the tree comes out the same as a serial
+// scan.
+func (r *run) scan(dir *Entry, info fs.FileInfo, ignores ignoreStack, parents *visit, depth int) {
+	dir.setInfo(info)
+	subdirs, ignores, parents := r.read(dir, ignores, parents)
+
+	// a streamed directory is passed on once its entries are known, so
+	// tests such as -empty see them, and its children are kept no longer
+	// than it takes to pass them on too
+	if r.Stream != nil {
+		r.emit(dir, depth)
+		for _, child := range dir.Children {
+			if !child.IsDir || child.Placeholder != "" {
+				r.emit(child, depth+1)
+			}
+		}
+		dir.Children = nil
+	}
+
+	var wg sync.WaitGroup
+	for _, sub := range subdirs {
+		r.spawn(&wg, func() { r.scan(sub.entry, sub.info, ignores, parents, depth+1) })
+	}
+	wg.Wait()
+}
+
+// subdir is a directory found by read that still has to be scanned.
+type subdir struct {
+	entry *Entry
+	info  fs.FileInfo
+}
+
+// read lists the entries of dir into its children and returns the
+// subdirectories to scan, along with the ignore rules and the chain of
+// parents that apply below dir.
+func (r *run) read(dir *Entry, ignores ignoreStack, parents *visit) ([]subdir, ignoreStack, *visit) {
+	path := dir.Path
+	if r.ctx.Err() != nil {
+		return nil, ignores, parents
+	}
+	entries, err := fs.ReadDir(r.fsys, path)
+	if err != nil {
+		return nil, ignores, parents
+	}
+
+	// with FollowLinks, remember the directories on the way down so a link
+	// back to one of them is not walked again
+	if r.FollowLinks && r.isOS() {
+		if key, ok := dirKey(path); ok {
+			parents = &visit{key: key, parent: parents}
+		}
+	}
+
+	// ignore files apply to the directory they live in and everything below
+	if r.gitRepo {
+		ignores = ignores.push(r.fsys, path, r.join(path, ".gitignore"))
+	}
+	ignores = ignores.push(r.fsys, path, r.join(path, IgnoreFileName))
+
+	dir.Children = make([]*Entry, 0, len(entries))
+	var subdirs []subdir
+	for _, entry := range entries {
+		fullPath := r.join(path, entry.Name())
+		if r.gitRepo && entry.Name() == ".git" {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		isLink := info.Mode()&fs.ModeSymlink != 0
+		var target fs.FileInfo
+		if isLink {
+			target, _ = fs.Stat(r.fsys, fullPath)
+		}
+		isDir := entry.IsDir() || r.FollowLinks && target != nil && target.IsDir()
+
+		if isHidden(entry.Name()) && r.Hidden != ShowHidden {
+			if r.Hidden == HideHidden {
+				atomic.AddInt64(&r.hidden, 1)
+				continue
+			}
+			if isDir {
+				// listed, but what is inside is skipped
+				atomic.AddInt64(&r.hidden, 1)
+				dir.Children = append(dir.Children, placeholder(entry.Name(), fullPath, "hidden"))
+				continue
+			}
+		}
+		if ignores.ignored(fullPath, isDir) || r.filtered(fullPath, isDir) {
+			if isDir && r.ShowExcluded {
+				dir.Children = append(dir.Children, placeholder(entry.Name(), fullPath, "excluded"))
+			}
+			continue
+		}
+
+		// a directory on another device is a mount point
+		mount := ""
+		if isDir {
+			dirInfo := info
+			if isLink {
+				dirInfo = target
+			}
+			if dev, ok := fileDevice(dirInfo); ok && dir.dev != 0 && dev != dir.dev {
+				mount = r.describeMount(fullPath)
+				if r.OneFileSystem {
+					dir.Children = append(dir.Children, placeholder(entry.Name(), fullPath, "mount: "+mount))
+					continue
+				}
+			}
+		}
+
+		child := &Entry{Name: entry.Name(), Path: fullPath, Mount: mount}
+		switch {
+		case isLink:
+			if r.scanLink(child, info, target, parents) {
+				subdirs = append(subdirs, subdir{child, target})
+			}
+		case isDir:
+			child.IsDir = true
+			subdirs = append(subdirs, subdir{child, info})
+		default:
+			child.Size = info.Size()
+			child.setInfo(info)
+		}
+		dir.Children = append(dir.Children, child)
+	}
+	return subdirs, ignores, parents
+}
+
+// prune drops every entry that neither matches nor leads to a match and
+// marks the matches. It reports whether anything below dir survived.
+func (r *run) prune(dir *Entry) bool {
+	var kept []*Entry
+	for _, e := range dir.Children {
+		if e.Placeholder != "" {
+			continue
+		}
+		e.Matched = r.Match(e)
+		if e.IsDir && r.prune(e) || e.Matched {
+			kept = append(kept, e)
+		}
+	}
+	dir.Children = kept
+	return len(kept) > 0
+}
+
+// summarize totals the size and entry counts of every directory below dir
+// from its current children, so pruned entries are left out, and adds the
+// entries to stats. With Match, stats count only the matches, not the
+// directories kept to lead to them.
+//
+// Files with several hard links add their bytes to the size only at the
+// first link met, while the apparent size counts every path.
+func (r *run) summarize(dir *Entry, stats *Stats) {
+	dir.Size, dir.Apparent, dir.Files, dir.Dirs = 0, 0, 0, 0
+	for _, e := range dir.Children {
+		counted := r.Match == nil || e.Matched
+		if e.LinkTarget != "" && counted {
+			stats.Links++
+			if e.Broken {
+				stats.BrokenLinks++
+			}
+		}
+		if e.Placeholder != "" {
+			continue
+		}
+		if e.IsDir {
+			r.summarize(e, stats)
+			dir.Files += e.Files
+			dir.Dirs += e.Dirs + 1
+			if counted {
+				stats.Dirs++
+			}
+		} else {
+			dir.Files++
+			e.Apparent = e.Size
+			e.ExtraLink = r.seenInode(e.inode)
+			if counted {
+				stats.Files++
+				stats.Apparent += e.Size
+				if !e.ExtraLink {
+					stats.Size += e.Size
+				}
+			}
+		}
+		dir.Apparent += e.Apparent
+		if !e.ExtraLink {
+			dir.Size += e.Size
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package walk
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+/* -------------------- sorting -------------------- */
+
+// Order is how the entries of each directory are listed.
+type Order struct {
+	// Key is one of SortKeys; "" keeps the order the entries were read
+	// in, which is by name.
+	Key string
+	// Reverse flips the order of Key, or the name order when Key is "".
+	Reverse bool
+	// Mixed interleaves directories and files instead of listing
+	// directories first.
+	Mixed bool
+}
+
+// sortKeys compare two entries. Size, mtime and count put the largest,
+// newest and fullest first, like ls -S and ls -t.
+var sortKeys = map[string]func(a, b *Entry) int{
+	"name":    func(a, b *Entry) int { return strings.Compare(a.Name, b.Name) },
+	"version": func(a, b *Entry) int { return naturalCompare(a.Name, b.Name) },
+	"size":    func(a, b *Entry) int { return compareInt(b.Size, a.Size) },
+	"mtime":   func(a, b *Entry) int { return b.ModTime.Compare(a.ModTime) },
+	"ext": func(a, b *Entry) int {
+		return strings.Compare(strings.ToLower(filepath.Ext(a.Name)), strings.ToLower(filepath.Ext(b.Name)))
+	},
+	"count": func(a, b *Entry) int { return compareInt(int64(b.Files+b.Dirs), int64(a.Files+a.Dirs)) },
+}
+
+// ValidSortKey reports whether key can be used as Order.Key.
+func ValidSortKey(key string) bool {
+	_, ok := sortKeys[key]
+	return ok || key == ""
+}
+
+// Ordered returns the children of dir in the given order.
+func Ordered(dir *Entry, order Order) []*Entry {
+	entries := append([]*Entry(nil), dir.Children...)
+
+	key := order.Key
+	if key == "" && order.Reverse {
+		key = "name"
+	}
+	if cmp, ok := sortKeys[key]; ok {
+		sort.SliceStable(entries, func(i, j int) bool {
+			a, b := entries[i], entries[j]
+			if order.Reverse {
+				a, b = b, a
+			}
+			if c := cmp(a, b); c != 0 {
+				return c < 0
+			}
+			// ties fall back to the name so the order stays predictable
+			return a.Name < b.Name
+		})
+	}
+
+	if order.Mixed {
+		return entries
+	}
+
+	// directories first, files later
+	var dirs, files []*Entry
+	for _, e := range entries {
+		if e.IsDir {
+			dirs = append(dirs, e)
+		} else {
+			files = append(files, e)
+		}
+	}
+	return append(dirs, files...)
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// naturalCompare orders names the way people read versions, comparing
+// runs of digits by their value so "v2" sorts before "v10".
+func naturalCompare(a, b string) int {
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			na, nb := digitRun(a), digitRun(b)
+			// compare numerically: first by length without leading
+			// zeros, then digit by digit
+			ta, tb := strings.TrimLeft(a[:na], "0"), strings.TrimLeft(b[:nb], "0")
+			if c := compareInt(int64(len(ta)), int64(len(tb))); c != 0 {
+				return c
+			}
+			if c := strings.Compare(ta, tb); c != 0 {
+				return c
+			}
+			a, b = a[na:], b[nb:]
+			continue
+		}
+		if a[0] != b[0] {
+			return compareInt(int64(a[0]), int64(b[0]))
+		}
+		a, b = a[1:], b[1:]
+	}
+	return compareInt(int64(len(a)), int64(len(b)))
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func digitRun(s string) int {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return i
+}
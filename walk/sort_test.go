@@ -0,0 +1,81 @@
+package walk
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNaturalCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"a", "a", 0},
+		{"", "", 0},
+		{"", "a", -1},
+		{"a", "ab", -1},
+		{"abc", "abd", -1},
+		{"v2", "v10", -1},
+		{"v10", "v2", 1},
+		{"x9y", "x10y", -1},
+		{"1.2.9", "1.2.10", -1},
+		{"1.10.0", "1.9.9", 1},
+		{"file01", "file1", 0},
+		{"file001", "file2", -1},
+		{"a1b2", "a1b10", -1},
+		{"10", "a", -1},
+		{"B", "a", -1},
+		{"99999999999999999999", "100000000000000000000", -1},
+	}
+	for _, tt := range tests {
+		if got := naturalCompare(tt.a, tt.b); got != tt.want {
+			t.Errorf("naturalCompare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestOrdered(t *testing.T) {
+	dir := &Entry{Children: []*Entry{
+		{Name: "v10.txt", Size: 1},
+		{Name: "sub", IsDir: true, Size: 5},
+		{Name: "v2.txt", Size: 9},
+		{Name: "abc", IsDir: true, Size: 2},
+	}}
+	tests := []struct {
+		order Order
+		want  []string
+	}{
+		{Order{}, []string{"sub", "abc", "v10.txt", "v2.txt"}},
+		{Order{Mixed: true}, []string{"v10.txt", "sub", "v2.txt", "abc"}},
+		{Order{Key: "name"}, []string{"abc", "sub", "v10.txt", "v2.txt"}},
+		{Order{Reverse: true}, []string{"sub", "abc", "v2.txt", "v10.txt"}},
+		{Order{Reverse: true, Mixed: true}, []string{"v2.txt", "v10.txt", "sub", "abc"}},
+		{Order{Key: "version"}, []string{"abc", "sub", "v2.txt", "v10.txt"}},
+		{Order{Key: "version", Reverse: true}, []string{"sub", "abc", "v10.txt", "v2.txt"}},
+		{Order{Key: "size"}, []string{"sub", "abc", "v2.txt", "v10.txt"}},
+		{Order{Key: "size", Mixed: true}, []string{"v2.txt", "sub", "abc", "v10.txt"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, e := range Ordered(dir, tt.order) {
+			got = append(got, e.Name)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Ordered(%+v) = %q, want %q", tt.order, got, tt.want)
+		}
+	}
+	if dir.Children[0].Name != "v10.txt" {
+		t.Error("Ordered reordered the children in place")
+	}
+}
+
+func TestValidSortKey(t *testing.T) {
+	for _, key := range []string{"", "name", "version", "size", "mtime", "ext", "count"} {
+		if !ValidSortKey(key) {
+			t.Errorf("ValidSortKey(%q) = false", key)
+		}
+	}
+	if ValidSortKey("color") {
+		t.Error(`ValidSortKey("color") = true`)
+	}
+}
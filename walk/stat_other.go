@@ -0,0 +1,37 @@
+//go:build !unix
+
+package walk
+
+import (
+	"io/fs"
+	"path/filepath"
+)
+
+// fileOwner returns empty ids where the platform has no unix ownership,
+// so ownership is left empty there.
+func fileOwner(info fs.FileInfo) (uid, gid string) {
+	return "", ""
+}
+
+// dirKey identifies the directory at path by its fully resolved path where
+// there are no inode numbers.
+func dirKey(path string) (string, bool) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", false
+	}
+	abs, err := filepath.Abs(resolved)
+	return abs, err == nil
+}
+
+// hardlinkKey is empty where the platform exposes no inode numbers, so
+// every link is counted.
+func hardlinkKey(info fs.FileInfo) string {
+	return ""
+}
+
+// fileDevice is unknown where the platform exposes no device numbers, so
+// no mount points are detected.
+func fileDevice(info fs.FileInfo) (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,42 @@
+package walk
+
+/* -------------------- streaming -------------------- */
+
+// emit passes e, found depth levels below the root, to Options.Stream and
+// adds it to the totals. With Match, everything but the root and the
+// matches is left out.
+func (r *run) emit(e *Entry, depth int) {
+	r.streamMu.Lock()
+	defer r.streamMu.Unlock()
+
+	if depth > 0 && r.Match != nil {
+		if e.Placeholder != "" {
+			return
+		}
+		if e.Matched = r.Match(e); !e.Matched {
+			return
+		}
+	}
+
+	stats := &r.streamed
+	if e.LinkTarget != "" {
+		stats.Links++
+		if e.Broken {
+			stats.BrokenLinks++
+		}
+	}
+	switch {
+	case depth == 0 || e.Placeholder != "":
+	case e.IsDir:
+		stats.Dirs++
+	default:
+		stats.Files++
+		e.Apparent = e.Size
+		e.ExtraLink = r.seenInode(e.inode)
+		stats.Apparent += e.Size
+		if !e.ExtraLink {
+			stats.Size += e.Size
+		}
+	}
+	r.Stream(e, depth)
+}
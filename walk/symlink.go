@@ -0,0 +1,51 @@
+package walk
+
+import "io/fs"
+
+/* -------------------- symbolic links -------------------- */
+
+// scanLink fills in e for a symbolic link. info describes the link itself
+// and target what it points to, or nil for a broken link. Unless links are
+// followed, a link counts as itself. It reports whether e is a linked
+// directory to be scanned like any other.
+func (r *run) scanLink(e *Entry, info, target fs.FileInfo, parents *visit) bool {
+	if lfs, ok := r.fsys.(ReadLinkFS); ok {
+		e.LinkTarget, _ = lfs.ReadLink(e.Path)
+	}
+
+	switch {
+	case target == nil:
+		e.Size, e.Broken = info.Size(), true
+		e.setInfo(info)
+	case !r.FollowLinks || !r.isOS():
+		e.Size = info.Size()
+		e.setInfo(info)
+	case !target.IsDir():
+		e.Size = target.Size()
+		e.setInfo(target)
+	default:
+		e.IsDir = true
+		if key, ok := dirKey(e.Path); ok && parents.contains(key) {
+			e.Placeholder = "loop"
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// visit is one directory on the path down from the root, keyed by device
+// and inode, so FollowLinks can tell when a link leads back up.
+type visit struct {
+	key    string
+	parent *visit
+}
+
+func (v *visit) contains(key string) bool {
+	for ; v != nil; v = v.parent {
+		if v.key == key {
+			return true
+		}
+	}
+	return false
+}
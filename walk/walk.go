@@ -0,0 +1,241 @@
+// Package walk scans a directory tree the way go-find does: in parallel,
+// honoring gitignore and .gofindignore files, with hidden-file, symlink,
+// hard-link and mount-point handling, and returns the tree with its
+// directory totals.
+//
+// The go-find command is built on this package:
+//
+//	w := walk.New(walk.Options{Gitignore: true})
+//	res, err := w.Walk(ctx, ".")
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Println(res.Stats.Files, "files,", res.Stats.Size, "bytes")
+package walk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"runtime"
+	"sync"
+)
+
+/* -------------------- options -------------------- */
+
+// HiddenMode selects how dotfiles and dot-directories are handled.
+type HiddenMode int
+
+const (
+	// HideHidden leaves hidden entries out and counts them in
+	// Stats.HiddenSkipped.
+	HideHidden HiddenMode = iota
+	// ShowHidden treats hidden entries like any other.
+	ShowHidden
+	// ShallowHidden lists hidden entries but does not descend into hidden
+	// directories, which are counted in Stats.HiddenSkipped.
+	ShallowHidden
+)
+
+// Options configures a Walker. The zero value scans everything but hidden
+// entries, serially, without reading any ignore files.
+type Options struct {
+	// FS is the filesystem to walk, such as an embed.FS, a zip.Reader or
+	// an fstest.MapFS, with root a path inside it. Nil means OS.
+	FS fs.FS
+
+	// Jobs is the number of directories read at once. Zero means one per
+	// CPU.
+	Jobs int
+
+	Hidden HiddenMode
+	// Gitignore skips the .git directory and what git ignores when the
+	// root lies inside a repository.
+	Gitignore bool
+	// Exclude and Include are gitignore-style patterns relative to the
+	// root. Excluded entries are pruned; when Include is set, only files
+	// matching one of its patterns are kept.
+	Exclude []string
+	Include []string
+	// ShowExcluded keeps a placeholder entry for every pruned directory.
+	ShowExcluded bool
+
+	// FollowLinks descends into linked directories and sizes linked files
+	// by their target, like du -L.
+	FollowLinks bool
+	// OneFileSystem does not descend into mount points, like find -xdev.
+	OneFileSystem bool
+	// Gitignore, FollowLinks, OneFileSystem and mount points, like hard
+	// links and ownership, apply to the OS filesystem only.
+
+	// Match, when set, keeps only the entries it matches and the
+	// directories leading to them, with Entry.Matched set on the matches.
+	Match func(e *Entry) bool
+
+	// Order is the order children are passed to Visitor in.
+	Order Order
+	// Visitor, when set, is called for every entry of the finished tree.
+	Visitor Visitor
+
+	// Stream, when set, is called with every entry as soon as it has been
+	// read, one call at a time, and the walk keeps no tree: Result.Root has
+	// no children and Stats count what was streamed. Entries come in the
+	// order they are read, parents before their children, with depth
+	// counting the levels below the root. A directory is passed on once it
+	// has been read, with Children set for the length of the call, so Match
+	// can look at them, but its totals are not known yet. With Match only
+	// the root and the matches are passed on.
+	Stream func(e *Entry, depth int)
+}
+
+/* -------------------- results -------------------- */
+
+// Result is the outcome of a walk.
+type Result struct {
+	// Root is the scanned directory, holding the whole tree. Its Name is
+	// the root as given to Walk and its Path the same path cleaned.
+	Root  *Entry
+	Stats Stats
+}
+
+// Stats totals a walk. Size counts hard-linked files once, Apparent counts
+// every path. With Options.Match only the matches are counted, and the
+// sizes are those of the matched files.
+type Stats struct {
+	Size          int64
+	Apparent      int64
+	Files         int
+	Dirs          int
+	Links         int
+	BrokenLinks   int
+	HiddenSkipped int
+}
+
+/* -------------------- visitor -------------------- */
+
+// Visitor is called for every entry of a finished walk, starting with the
+// root at depth 0, parents before their children, in Options.Order.
+type Visitor interface {
+	Visit(e *Entry, depth int) error
+}
+
+// VisitorFunc adapts a function to the Visitor interface.
+type VisitorFunc func(e *Entry, depth int) error
+
+// Visit calls f(e, depth).
+func (f VisitorFunc) Visit(e *Entry, depth int) error {
+	return f(e, depth)
+}
+
+// SkipDir, returned by a Visitor for a directory, skips its children.
+var SkipDir = errors.New("skip this directory")
+
+/* -------------------- walker -------------------- */
+
+// Walker scans directory trees with a fixed set of options. It is safe to
+// run several walks with the same Walker at once.
+type Walker struct {
+	opts Options
+}
+
+// New returns a Walker using opts.
+func New(opts Options) *Walker {
+	if opts.Jobs < 1 {
+		opts.Jobs = runtime.NumCPU()
+	}
+	return &Walker{opts: opts}
+}
+
+// Walk scans the directory root. Unreadable entries are skipped. When ctx
+// is cancelled the scan stops and Walk returns ctx.Err().
+func (w *Walker) Walk(ctx context.Context, root string) (*Result, error) {
+	fsys := w.opts.FS
+	if fsys == nil {
+		fsys = OS
+	}
+	info, err := fs.Stat(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+
+	r := &run{
+		Options:  w.opts,
+		ctx:      ctx,
+		fsys:     fsys,
+		slots:    make(chan struct{}, w.opts.Jobs-1),
+		seen:     map[string]bool{},
+		excludes: compileFilters(root, w.opts.Exclude),
+		includes: compileFilters(root, w.opts.Include),
+	}
+	ignores := r.gitIgnores(root)
+	// the root keeps its name as given, but its path is cleaned like
+	// those of its children, which are joined onto it
+	rootEntry := &Entry{Name: root, Path: r.clean(root), IsDir: true}
+	r.scan(rootEntry, info, ignores, nil, 0)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	res := &Result{Root: rootEntry}
+	if w.opts.Stream != nil {
+		res.Stats = r.streamed
+		rootEntry.Size, rootEntry.Apparent = r.streamed.Size, r.streamed.Apparent
+		rootEntry.Files, rootEntry.Dirs = r.streamed.Files, r.streamed.Dirs
+	} else {
+		if w.opts.Match != nil {
+			r.prune(rootEntry)
+		}
+		r.summarize(rootEntry, &res.Stats)
+	}
+	res.Stats.HiddenSkipped = int(r.hidden)
+
+	if w.opts.Visitor != nil {
+		if err := r.visit(rootEntry, 0); err != nil && err != SkipDir {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// run is the state of a single walk.
+type run struct {
+	Options
+	ctx  context.Context
+	fsys fs.FS
+
+	// slots holds a token for every goroutine scanning besides the one
+	// that called Walk
+	slots chan struct{}
+	// gitRepo is set once the root is known to be inside a repository
+	// whose ignore files apply
+	gitRepo            bool
+	excludes, includes *ignoreFile
+	// hidden counts the hidden entries left out, updated atomically
+	hidden int64
+	// seen records the hard-linked files already counted by summarize
+	// or emit
+	seen map[string]bool
+
+	// streamMu serializes calls to Stream, which total into streamed
+	streamMu sync.Mutex
+	streamed Stats
+
+	mounts     map[string]string
+	mountsOnce sync.Once
+}
+
+func (r *run) visit(e *Entry, depth int) error {
+	if err := r.Visitor.Visit(e, depth); err != nil {
+		return err
+	}
+	for _, child := range Ordered(e, r.Order) {
+		if err := r.visit(child, depth+1); err != nil && err != SkipDir {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package walk
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+// testFS is a small tree with 39 bytes in five files below three
+// directories, next to two hidden entries.
+func testFS() fstest.MapFS {
+	file := func(size int) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(strings.Repeat("x", size))}
+	}
+	return fstest.MapFS{
+		"top.log":       file(13),
+		"a/x.log":       file(3),
+		"a/b/y.txt":     file(5),
+		"a/b/z.log":     file(7),
+		"c/d.txt":       file(11),
+		".hidden/h.log": file(1),
+		".dot":          file(1),
+	}
+}
+
+func walkTest(t *testing.T, opts Options) *Result {
+	t.Helper()
+	if opts.FS == nil {
+		opts.FS = testFS()
+	}
+	res, err := New(opts).Walk(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Walk: %v", err)
+	}
+	return res
+}
+
+// paths lists the entries below root in Ordered order, directories with a
+// trailing slash.
+func paths(root *Entry) []string {
+	var out []string
+	for _, e := range Ordered(root, Order{Key: "name"}) {
+		if e.IsDir {
+			out = append(out, e.Path+"/")
+			out = append(out, paths(e)...)
+		} else {
+			out = append(out, e.Path)
+		}
+	}
+	return out
+}
+
+func TestWalkStats(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want Stats
+	}{
+		{"default", Options{}, Stats{Size: 39, Apparent: 39, Files: 5, Dirs: 3, HiddenSkipped: 2}},
+		{"hidden", Options{Hidden: ShowHidden}, Stats{Size: 41, Apparent: 41, Files: 7, Dirs: 4}},
+		{"exclude", Options{Exclude: []string{"b/"}}, Stats{Size: 27, Apparent: 27, Files: 3, Dirs: 2, HiddenSkipped: 2}},
+		{"include", Options{Include: []string{"*.txt"}}, Stats{Size: 16, Apparent: 16, Files: 2, Dirs: 3, HiddenSkipped: 2}},
+		{"serial", Options{Jobs: 1}, Stats{Size: 39, Apparent: 39, Files: 5, Dirs: 3, HiddenSkipped: 2}},
+	}
+	for _, tt := range tests {
+		res := walkTest(t, tt.opts)
+		if res.Stats != tt.want {
+			t.Errorf("%s: Stats = %+v, want %+v", tt.name, res.Stats, tt.want)
+		}
+		root := res.Root
+		if root.Size != tt.want.Size || root.Files != tt.want.Files || root.Dirs != tt.want.Dirs {
+			t.Errorf("%s: root totals %d bytes, %d files, %d dirs, want %d, %d, %d",
+				tt.name, root.Size, root.Files, root.Dirs, tt.want.Size, tt.want.Files, tt.want.Dirs)
+		}
+	}
+}
+
+func TestWalkDirectoryTotals(t *testing.T) {
+	res := walkTest(t, Options{})
+	var a *Entry
+	for _, e := range res.Root.Children {
+		if e.Name == "a" {
+			a = e
+		}
+	}
+	if a == nil {
+		t.Fatal("a/ missing")
+	}
+	if a.Size != 15 || a.Files != 3 || a.Dirs != 1 {
+		t.Errorf("a/ totals %d bytes, %d files, %d dirs, want 15, 3, 1", a.Size, a.Files, a.Dirs)
+	}
+}
+
+func TestWalkShallowHidden(t *testing.T) {
+	res := walkTest(t, Options{Hidden: ShallowHidden})
+	var hidden *Entry
+	for _, e := range res.Root.Children {
+		if e.Name == ".hidden" {
+			hidden = e
+		}
+	}
+	if hidden == nil || hidden.Placeholder != "hidden" || len(hidden.Children) != 0 {
+		t.Errorf(".hidden = %+v, want an empty placeholder", hidden)
+	}
+	if res.Stats.HiddenSkipped != 1 {
+		t.Errorf("HiddenSkipped = %d, want 1 for .hidden", res.Stats.HiddenSkipped)
+	}
+}
+
+func TestWalkShowExcluded(t *testing.T) {
+	res := walkTest(t, Options{Exclude: []string{"b/"}, ShowExcluded: true})
+	want := []string{"a/", "a/b/", "a/x.log", "c/", "c/d.txt", "top.log"}
+	if got := paths(res.Root); !reflect.DeepEqual(got, want) {
+		t.Errorf("paths = %q, want %q", got, want)
+	}
+}
+
+func TestWalkMatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		match func(e *Entry) bool
+		paths []string
+		stats Stats
+	}{
+		{
+			"files",
+			func(e *Entry) bool { return strings.HasSuffix(e.Name, ".log") },
+			[]string{"a/", "a/b/", "a/b/z.log", "a/x.log", "top.log"},
+			Stats{Size: 23, Apparent: 23, Files: 3, HiddenSkipped: 2},
+		},
+		{
+			"directory",
+			func(e *Entry) bool { return e.Name == "b" },
+			[]string{"a/", "a/b/"},
+			Stats{Dirs: 1, HiddenSkipped: 2},
+		},
+		{
+			"nothing",
+			func(e *Entry) bool { return false },
+			nil,
+			Stats{HiddenSkipped: 2},
+		},
+	}
+	for _, tt := range tests {
+		res := walkTest(t, Options{Match: tt.match})
+		if got := paths(res.Root); !reflect.DeepEqual(got, tt.paths) {
+			t.Errorf("%s: paths = %q, want %q", tt.name, got, tt.paths)
+		}
+		if res.Stats != tt.stats {
+			t.Errorf("%s: Stats = %+v, want %+v", tt.name, res.Stats, tt.stats)
+		}
+	}
+}
+
+func TestWalkMatchMarks(t *testing.T) {
+	res := walkTest(t, Options{Match: func(e *Entry) bool { return e.Name == "z.log" }})
+	var check func(e *Entry)
+	check = func(e *Entry) {
+		for _, c := range e.Children {
+			if c.Matched != (c.Name == "z.log") {
+				t.Errorf("%s: Matched = %v", c.Path, c.Matched)
+			}
+			check(c)
+		}
+	}
+	check(res.Root)
+}
+
+func TestWalkVisitor(t *testing.T) {
+	var got []string
+	visit := VisitorFunc(func(e *Entry, depth int) error {
+		got = append(got, strings.Repeat(" ", depth)+e.Name)
+		if e.Name == "a" {
+			return SkipDir
+		}
+		return nil
+	})
+	walkTest(t, Options{Order: Order{Key: "name"}, Visitor: visit})
+	want := []string{".", " a", " c", "  d.txt", " top.log"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("visited %q, want %q", got, want)
+	}
+}
+
+func TestWalkVisitorError(t *testing.T) {
+	stop := errors.New("stop")
+	visit := VisitorFunc(func(e *Entry, depth int) error {
+		if e.Name == "y.txt" {
+			return stop
+		}
+		return nil
+	})
+	_, err := New(Options{FS: testFS(), Visitor: visit}).Walk(context.Background(), ".")
+	if err != stop {
+		t.Errorf("Walk = %v, want the visitor's error", err)
+	}
+}
+
+func TestWalkStream(t *testing.T) {
+	var mu sync.Mutex
+	var got []string
+	stream := func(e *Entry, depth int) {
+		mu.Lock()
+		defer mu.Unlock()
+		if depth > 0 {
+			got = append(got, e.Path)
+		}
+	}
+	match := func(e *Entry) bool { return strings.HasSuffix(e.Name, ".log") }
+	res := walkTest(t, Options{Match: match, Stream: stream})
+	if len(res.Root.Children) != 0 {
+		t.Error("streaming kept the tree")
+	}
+	if want := walkTest(t, Options{Match: match}).Stats; res.Stats != want {
+		t.Errorf("streamed Stats = %+v, want %+v", res.Stats, want)
+	}
+	if len(got) != 3 {
+		t.Errorf("streamed %q, want the three .log files", got)
+	}
+}
+
+func TestWalkNotDirectory(t *testing.T) {
+	if _, err := New(Options{FS: testFS()}).Walk(context.Background(), "top.log"); err == nil {
+		t.Error("walking a file succeeded")
+	}
+	if _, err := New(Options{FS: testFS()}).Walk(context.Background(), "missing"); err == nil {
+		t.Error("walking a missing directory succeeded")
+	}
+}
+
+func TestWalkCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := New(Options{FS: testFS()}).Walk(ctx, "."); err != context.Canceled {
+		t.Errorf("Walk = %v, want context.Canceled", err)
+	}
+}
+
+func TestWalkRootPath(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "c")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "d.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	root := dir + string(filepath.Separator) + "." + string(filepath.Separator)
+	res, err := New(Options{}).Walk(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Root.Name != root || res.Root.Path != dir {
+		t.Errorf("root name %q, path %q, want %q and %q", res.Root.Name, res.Root.Path, root, dir)
+	}
+	if child := res.Root.Children[0]; filepath.Dir(child.Path) != res.Root.Path {
+		t.Errorf("child path %q is not below the root path %q", child.Path, res.Root.Path)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/saurav-tiwari03/go-find/walk"
+)
+
+/* -------------------- XML output -------------------- */
+
+// renderXML writes the tree in the layout of tree -X -s, so consumers of
+// tree's XML can read it unchanged. Directory sizes are their totals, as
+// with tree --du.
+func renderXML(w io.Writer, res *walk.Result) error {
+	fmt.Fprintln(w, `<?xml version="1.0"?>`)
+	fmt.Fprintln(w, "<tree>")
+	xmlEntry(w, res.Root, 0)
+	fmt.Fprintln(w, "  <report>")
+	fmt.Fprintf(w, "    <directories>%d</directories>\n", res.Stats.Dirs)
+	fmt.Fprintf(w, "    <files>%d</files>\n", res.Stats.Files)
+	fmt.Fprintln(w, "  </report>")
+	fmt.Fprintln(w, "</tree>")
+	return nil
+}
+
+// xmlEntry writes e, which is depth levels below the root, and its
+// children.
+func xmlEntry(w io.Writer, e *walk.Entry, depth int) {
+	indent := strings.Repeat("  ", depth+1)
+	tag := entryType(e)
+
+	fmt.Fprintf(w, "%s<%s name=\"%s\"", indent, tag, xmlAttr(e.Name))
+	if e.LinkTarget != "" {
+		fmt.Fprintf(w, " target=\"%s\"", xmlAttr(e.LinkTarget))
+	}
+	fmt.Fprintf(w, " size=\"%d\">", e.Size)
+
+	if !e.IsDir || !expanded(e, depth) || len(e.Children) == 0 {
+		fmt.Fprintf(w, "</%s>\n", tag)
+		return
+	}
+	fmt.Fprintln(w)
+	for _, child := range walk.Ordered(e, walkOpts.Order) {
+		xmlEntry(w, child, depth+1)
+	}
+	fmt.Fprintf(w, "%s</%s>\n", indent, tag)
+}
+
+func xmlAttr(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}